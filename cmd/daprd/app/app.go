@@ -196,6 +196,8 @@ func runWithContext(ctx context.Context, opts *options.Options) error {
 				SentryAddress:                 opts.SentryAddress,
 				MaxRequestSize:                opts.MaxRequestSize,
 				ReadBufferSize:                opts.ReadBufferSize,
+				MaxInvocationChainLength:      opts.MaxInvocationChainLength,
+				InvocationLoopByMethod:        opts.InvocationLoopByMethod,
 				UnixDomainSocket:              opts.UnixDomainSocket,
 				DaprGracefulShutdownSeconds:   opts.DaprGracefulShutdownSeconds,
 				DaprBlockShutdownDuration:     opts.DaprBlockShutdownDuration,
@@ -29,6 +29,7 @@ import (
 	"github.com/dapr/dapr/pkg/config/protocol"
 	"github.com/dapr/dapr/pkg/cors"
 	injectorconsts "github.com/dapr/dapr/pkg/injector/consts"
+	invokev1 "github.com/dapr/dapr/pkg/messaging/v1"
 	"github.com/dapr/dapr/pkg/metrics"
 	"github.com/dapr/dapr/pkg/modes"
 	"github.com/dapr/dapr/pkg/runtime"
@@ -81,6 +82,8 @@ type Options struct {
 	Config                        []string
 	UnixDomainSocket              string
 	ReadBufferSize                int // In bytes
+	MaxInvocationChainLength      int
+	InvocationLoopByMethod        bool
 	DisableBuiltinK8sSecretStore  bool
 	AppHealthCheckPath            string
 	AppChannelAddress             string
@@ -159,6 +162,8 @@ func New(origArgs []string) (*Options, error) {
 	fs.IntVar(&readBufferSizeKB, "dapr-http-read-buffer-size", runtime.DefaultReadBufferSize>>10, "Max size of read buffer, in KB (also used to handle request headers)")
 	fs.MarkDeprecated("dapr-http-read-buffer-size", "use '--read-buffer-size "+strconv.Itoa(runtime.DefaultReadBufferSize>>10)+"Ki'")
 	fs.StringVar(&readBufferSize, "read-buffer-size", strconv.Itoa(runtime.DefaultReadBufferSize>>10)+"Ki", "Max size of read buffer, as a resource quantity (also used to handle request headers)")
+	fs.IntVar(&opts.MaxInvocationChainLength, "max-invocation-chain-length", invokev1.DefaultMaxInvocationChainLength, "Max number of invocations kept in the invocation chain of service invocation requests, used to detect invocation loops; 0 disables loop detection. Chains are only trusted when forwarded by the app from the request it handles, so apps must forward the dapr-invocation-chain header for loops to be detected")
+	fs.BoolVar(&opts.InvocationLoopByMethod, "invocation-loop-detection-by-method", false, "Detect invocation loops on the same method of an app, instead of any method of the app, to allow callbacks; must be set on the sidecars of all the apps in the chain")
 	fs.StringVar(&opts.UnixDomainSocket, "unix-domain-socket", "", "Path to a unix domain socket dir mount. If specified, Dapr API servers will use Unix Domain Sockets")
 	fs.IntVar(&opts.DaprGracefulShutdownSeconds, "dapr-graceful-shutdown-seconds", int(runtime.DefaultGracefulShutdownDuration/time.Second), "Graceful shutdown time in seconds")
 	fs.DurationVar(opts.DaprBlockShutdownDuration, "dapr-block-shutdown-duration", 0, "If enabled, will block graceful shutdown after terminate signal is received until either the given duration has elapsed or the app reports unhealthy. Disabled by default")
@@ -20,6 +20,7 @@ import (
 	"io"
 	"net/http"
 
+	"go.opentelemetry.io/otel/attribute"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/anypb"
@@ -31,6 +32,7 @@ import (
 	"github.com/dapr/dapr/pkg/api/grpc/metadata"
 	diag "github.com/dapr/dapr/pkg/diagnostics"
 	diagConsts "github.com/dapr/dapr/pkg/diagnostics/consts"
+	diagUtils "github.com/dapr/dapr/pkg/diagnostics/utils"
 	"github.com/dapr/dapr/pkg/messages"
 	"github.com/dapr/dapr/pkg/messaging"
 	"github.com/dapr/dapr/pkg/messaging/method"
@@ -60,7 +62,10 @@ func (a *api) CallLocal(ctx context.Context, in *internalv1pb.InternalInvokeRequ
 	}
 
 	// Diagnostics
-	callerAppID := a.callLocalRecordRequest(req.Proto())
+	callerAppID := a.callLocalRecordRequest(ctx, req.Proto())
+
+	// Trust the invocation chain while the app handles the request, for the invocations it makes.
+	defer invokev1.TrackInvocationChain(req.Metadata())()
 
 	var statusCode int32
 	defer func() {
@@ -71,6 +76,11 @@ func (a *api) CallLocal(ctx context.Context, in *internalv1pb.InternalInvokeRequ
 	res, err := appChannel.InvokeMethod(ctx, req, "")
 	if err != nil {
 		statusCode = int32(codes.Internal)
+		// A payload too large keeps its status, so the caller can respond with 413.
+		if invokev1.HTTPStatusFromError(err) == http.StatusRequestEntityTooLarge {
+			statusCode = int32(codes.ResourceExhausted)
+			return nil, err
+		}
 		return nil, status.Errorf(codes.Internal, messages.ErrChannelInvoke, err)
 	} else {
 		statusCode = res.Status().GetCode()
@@ -129,7 +139,10 @@ func (a *api) CallLocalStream(stream internalv1pb.ServiceInvocation_CallLocalStr
 	}
 
 	// Diagnostics
-	callerAppID := a.callLocalRecordRequest(req.Proto())
+	callerAppID := a.callLocalRecordRequest(ctx, req.Proto())
+
+	// Trust the invocation chain while the app handles the request, for the invocations it makes.
+	defer invokev1.TrackInvocationChain(req.Metadata())()
 
 	var statusCode int32
 	defer func() {
@@ -200,6 +213,9 @@ func (a *api) CallLocalStream(stream internalv1pb.ServiceInvocation_CallLocalStr
 	// Submit the request to the app
 	res, err := appChannel.InvokeMethod(ctx, req, "")
 	if err != nil {
+		if invokev1.HTTPStatusFromError(err) == http.StatusRequestEntityTooLarge {
+			return err
+		}
 		return status.Errorf(codes.Internal, messages.ErrChannelInvoke, err)
 	}
 
@@ -430,7 +446,7 @@ func (a *api) callLocalValidateACL(ctx context.Context, req *invokev1.InvokeMeth
 // diag.DefaultMonitoring.ServiceInvocationResponseSent(callerAppID, req.Message().Method, statusCode)
 // }()
 // ```
-func (a *api) callLocalRecordRequest(req *internalv1pb.InternalInvokeRequest) (callerAppID string) {
+func (a *api) callLocalRecordRequest(ctx context.Context, req *internalv1pb.InternalInvokeRequest) (callerAppID string) {
 	callerIDHeader, ok := req.GetMetadata()[invokev1.CallerIDHeader]
 	if ok && len(callerIDHeader.GetValues()) > 0 {
 		callerAppID = callerIDHeader.GetValues()[0]
@@ -439,6 +455,25 @@ func (a *api) callLocalRecordRequest(req *internalv1pb.InternalInvokeRequest) (c
 	}
 
 	diag.DefaultMonitoring.ServiceInvocationRequestReceived(callerAppID)
+	diag.DefaultMonitoring.ServiceInvocationChainDepth(callerAppID, len(invokev1.InvocationChain(req.GetMetadata())))
+	protocolVersion := invokev1.ProtocolVersionFromMetadata(req.GetMetadata())
+	if invokev1.IsGRPCProtocol(req.GetMetadata()) {
+		diag.DefaultMonitoring.ServiceInvocationRequestOrigin(diagConsts.DaprAPIGRPCSpanAttrValue, protocolVersion)
+		diag.DefaultMonitoring.ServiceInvocationRequestMetadataCount(diagConsts.DaprAPIGRPCSpanAttrValue, len(req.GetMetadata()))
+	} else {
+		diag.DefaultMonitoring.ServiceInvocationRequestOrigin(diagConsts.DaprAPIHTTPSpanAttrValue, protocolVersion)
+		diag.DefaultMonitoring.ServiceInvocationRequestMetadataCount(diagConsts.DaprAPIHTTPSpanAttrValue, len(req.GetMetadata()))
+
+		// Add the HTTP method to the span so traces can be filtered by it.
+		method := invokev1.HTTPMethodFromMetadata(req.GetMetadata())
+		// A NONE verb means the request has no HTTP method, as for gRPC calls, so the tag is skipped.
+		if verb := req.GetMessage().GetHttpExtension().GetVerb(); method == "" && verb != commonv1pb.HTTPExtension_NONE {
+			method = verb.String()
+		}
+		if method != "" {
+			diagUtils.SpanFromContext(ctx).SetAttributes(attribute.String(diagConsts.OtelSpanConvHTTPRequestMethodAttributeKey, method))
+		}
+	}
 
 	return
 }
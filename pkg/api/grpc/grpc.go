@@ -177,6 +177,7 @@ func (a *api) validateAndGetPubsubAndTopic(pubsubName, topic string, reqMeta map
 }
 
 func (a *api) PublishEvent(ctx context.Context, in *runtimev1pb.PublishEventRequest) (*emptypb.Empty, error) {
+	diag.DefaultGRPCMonitoring.SetTopic(ctx, in.GetTopic())
 	thepubsub, pubsubName, topic, rawPayload, validationErr := a.validateAndGetPubsubAndTopic(in.GetPubsubName(), in.GetTopic(), in.GetMetadata())
 	if validationErr != nil {
 		apiServerLogger.Debug(validationErr)
@@ -297,7 +298,9 @@ func (a *api) InvokeService(ctx context.Context, in *runtimev1pb.InvokeServiceRe
 	policyRunner := resiliency.NewRunner[*invokeServiceResp](ctx, policyDef)
 	resp, err := policyRunner(func(ctx context.Context) (*invokeServiceResp, error) {
 		rResp := &invokeServiceResp{}
+		invokeStart := time.Now()
 		imr, rErr := a.directMessaging.Invoke(ctx, in.GetId(), req)
+		diag.DefaultGRPCMonitoring.AddDownstreamDuration(ctx, time.Since(invokeStart))
 		if imr != nil {
 			// Read the entire message in memory then close imr
 			pd, pdErr := imr.ProtoWithData()
@@ -373,6 +376,7 @@ func (a *api) BulkPublishEvent(ctx context.Context, in *runtimev1pb.BulkPublishR
 }
 
 func (a *api) bulkPublishEvent(ctx context.Context, in *runtimev1pb.BulkPublishRequest, spanName string) (*runtimev1pb.BulkPublishResponse, error) {
+	diag.DefaultGRPCMonitoring.SetTopic(ctx, in.GetTopic())
 	thepubsub, pubsubName, topic, rawPayload, validationErr := a.validateAndGetPubsubAndTopic(in.GetPubsubName(), in.GetTopic(), in.GetMetadata())
 
 	if validationErr != nil {
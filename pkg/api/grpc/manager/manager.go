@@ -149,6 +149,7 @@ func (g *Manager) createLocalConnection(parentCtx context.Context, port int, ena
 	if diag.DefaultGRPCMonitoring.IsEnabled() {
 		opts = append(opts,
 			grpc.WithUnaryInterceptor(diag.DefaultGRPCMonitoring.UnaryClientInterceptor()),
+			grpc.WithStatsHandler(diag.DefaultGRPCMonitoring.ClientStatsHandler()),
 		)
 	}
 
@@ -217,6 +218,7 @@ func (g *Manager) connectRemote(
 	if diag.DefaultGRPCMonitoring.IsEnabled() {
 		opts = append(opts,
 			grpc.WithUnaryInterceptor(diag.DefaultGRPCMonitoring.UnaryClientInterceptor()),
+			grpc.WithStatsHandler(diag.DefaultGRPCMonitoring.ClientStatsHandler()),
 		)
 	}
 
@@ -15,6 +15,7 @@ package metadata
 
 import (
 	"context"
+	"time"
 
 	"google.golang.org/grpc"
 	grpcMetadata "google.golang.org/grpc/metadata"
@@ -27,6 +28,9 @@ type MD = grpcMetadata.MD
 // Used as context key only
 type ctxKey struct{}
 
+// Used as context key only, for the time the request arrived
+type arrivalCtxKey struct{}
+
 // FromIncomingContext returns the incoming metadata in ctx if it exists.
 func FromIncomingContext(ctx context.Context) (MD, bool) {
 	md, ok := ctx.Value(ctxKey{}).(MD)
@@ -36,6 +40,12 @@ func FromIncomingContext(ctx context.Context) (MD, bool) {
 	return md, true
 }
 
+// ArrivalTimeFromContext returns the time the request arrived at the server, as recorded by SetMetadataInTapHandle.
+func ArrivalTimeFromContext(ctx context.Context) (time.Time, bool) {
+	t, ok := ctx.Value(arrivalCtxKey{}).(time.Time)
+	return t, ok
+}
+
 // SetMetadataInContextUnary sets the metadata in the context for an unary gRPC invocation.
 func SetMetadataInContextUnary(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
 	// Because metadata.FromIncomingContext re-allocates the entire map every time to ensure the keys are lowercased, we can do it once and re-use that after
@@ -47,7 +57,10 @@ func SetMetadataInContextUnary(ctx context.Context, req any, _ *grpc.UnaryServer
 }
 
 // SetMetadataInTapHandle sets the metadata in the context for a streaming gRPC invocation.
+// It also records the time the request arrived, before it is admitted to a handler.
 func SetMetadataInTapHandle(ctx context.Context, _ *tap.Info) (context.Context, error) {
+	ctx = context.WithValue(ctx, arrivalCtxKey{}, time.Now())
+
 	// Because metadata.FromIncomingContext re-allocates the entire map every time to ensure the keys are lowercased, we can do it once and re-use that after
 	meta, ok := grpcMetadata.FromIncomingContext(ctx)
 	if ok && len(meta) > 0 {
@@ -34,6 +34,7 @@ import (
 	actorerrors "github.com/dapr/dapr/pkg/actors/errors"
 	"github.com/dapr/dapr/pkg/actors/reminders"
 	"github.com/dapr/dapr/pkg/api/http/endpoints"
+	diag "github.com/dapr/dapr/pkg/diagnostics"
 	diagConsts "github.com/dapr/dapr/pkg/diagnostics/consts"
 	"github.com/dapr/dapr/pkg/messages"
 	methodutil "github.com/dapr/dapr/pkg/messaging/method"
@@ -57,7 +58,7 @@ var endpointGroupActorV1Misc = &endpoints.EndpointGroup{
 }
 
 func appendActorStateSpanAttributesFn(r *http.Request, m map[string]string) {
-	m[diagConsts.DaprAPIActorTypeID] = chi.URLParam(r, actorTypeParam) + "." + chi.URLParam(r, actorIDParam)
+	m[diagConsts.DaprAPIActorTypeID] = diag.ActorSpanAttributeValue(chi.URLParam(r, actorTypeParam), chi.URLParam(r, actorIDParam))
 	m[diagConsts.DBSystemSpanAttributeKey] = diagConsts.StateBuildingBlockType
 	m[diagConsts.DBConnectionStringSpanAttributeKey] = diagConsts.StateBuildingBlockType
 	m[diagConsts.DBStatementSpanAttributeKey] = r.Method + " " + r.URL.Path
@@ -66,7 +67,7 @@ func appendActorStateSpanAttributesFn(r *http.Request, m map[string]string) {
 
 func appendActorInvocationSpanAttributesFn(r *http.Request, m map[string]string) {
 	actorType := chi.URLParam(r, actorTypeParam)
-	actorTypeID := actorType + "." + chi.URLParam(r, actorIDParam)
+	actorTypeID := diag.ActorSpanAttributeValue(actorType, chi.URLParam(r, actorIDParam))
 	m[diagConsts.DaprAPIActorTypeID] = actorTypeID
 	m[diagConsts.GrpcServiceSpanAttributeKey] = "ServiceInvocation"
 	m[diagConsts.NetPeerNameSpanAttributeKey] = actorTypeID
@@ -80,7 +81,7 @@ func appendActorInvocationSpanAttributesFn(r *http.Request, m map[string]string)
 // endpoint name and actorType are used instead, dropping actorId and name.
 func appendActorReminderTimerSpanAttributesFn(r *http.Request, m map[string]string) {
 	actorType := chi.URLParam(r, actorTypeParam)
-	m[diagConsts.DaprAPIActorTypeID] = actorType + "." + chi.URLParam(r, actorIDParam)
+	m[diagConsts.DaprAPIActorTypeID] = diag.ActorSpanAttributeValue(actorType, chi.URLParam(r, actorIDParam))
 
 	endpointData, _ := r.Context().Value(endpoints.EndpointCtxKey{}).(*endpoints.EndpointCtxData)
 	m[diagConsts.DaprAPISpanNameInternal] = endpointData.GetEndpointName() + "/" + actorType
@@ -26,6 +26,7 @@ import (
 	"sync/atomic"
 
 	"github.com/cenkalti/backoff/v4"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -171,8 +172,9 @@ func (a *api) onDirectMessage(w http.ResponseWriter, r *http.Request) {
 				msg:        apiErr.JSONErrorValue(),
 			}
 
-			if status.Code(rErr) == codes.PermissionDenied {
-				invokeErr.statusCode = invokev1.HTTPStatusFromCode(codes.PermissionDenied)
+			// Errors that carry their HTTP status, such as a payload too large, keep it too.
+			if httpCode := invokev1.HTTPStatusFromError(rErr); status.Code(rErr) == codes.PermissionDenied || httpCode == http.StatusRequestEntityTooLarge {
+				invokeErr.statusCode = httpCode
 			}
 
 			// If this is a streaming request, wrap transport errors as
@@ -194,16 +196,23 @@ func (a *api) onDirectMessage(w http.ResponseWriter, r *http.Request) {
 
 		// Construct response if not HTTP
 		resStatus := rResp.Status()
+		// Length of the body when it is converted, or -1 if the body is forwarded as-is.
+		convertedBodyLen := -1
 		if !rResp.IsHTTPResponse() {
 			// TODO: Update type to use int32
 			//nolint:gosec
-			statusCode := int32(invokev1.HTTPStatusFromCode(codes.Code(resStatus.GetCode())))
+			statusCode := int32(invokev1.HTTPStatusFromError(status.ErrorProto(&spb.Status{
+				Code:    resStatus.GetCode(),
+				Message: resStatus.GetMessage(),
+				Details: resStatus.GetDetails(),
+			})))
 			if statusCode != http.StatusOK {
 				// Close the response to replace the body
 				_ = rResp.Close()
 				var body []byte
-				body, rErr = invokev1.ProtobufToJSON(resStatus)
+				body, rErr = invokev1.ProtobufToJSONContext(ctx, resStatus)
 				rResp.WithRawDataBytes(body)
+				convertedBodyLen = len(body)
 				resStatus.Code = statusCode
 				if rErr != nil {
 					return rResp, invokeError{
@@ -241,6 +250,7 @@ func (a *api) onDirectMessage(w http.ResponseWriter, r *http.Request) {
 		if len(headers) > 0 {
 			invokev1.InternalMetadataToHTTPHeader(r.Context(), headers, w.Header().Add)
 		}
+		invokev1.SetContentLength(w.Header().Set, convertedBodyLen)
 
 		if ct := rResp.ContentType(); ct != "" {
 			w.Header().Set("content-type", ct)
@@ -365,6 +365,20 @@ type MetricSpec struct {
 	//    1, 2, 3, 4, 5, 6, 8, 10, 13, 16, 20, 25, 30, 40, 50, 65, 80, 100, 130, 160, 200, 250, 300, 400, 500, 650, 800, 1,000, 2,000, 5,000, 10,000, 20,000, 50,000, 100,000.
 	// +optional
 	LatencyDistributionBuckets *[]int `json:"latencyDistributionBuckets,omitempty"`
+	// Push configures pushing the gRPC metrics to an OTLP endpoint, in addition to exposing them for scraping.
+	// +optional
+	Push *MetricPushSpec `json:"push,omitempty"`
+}
+
+// MetricPushSpec defines the configuration for pushing metrics to an OTLP gRPC endpoint.
+type MetricPushSpec struct {
+	EndpointAddress string `json:"endpointAddress"`
+	// Interval between pushes. Defaults to 1m.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// Defaults to true.
+	// +optional
+	IsSecure *bool `json:"isSecure,omitempty"`
 }
 
 // MetricHTTP defines configuration for metrics for the HTTP server
@@ -495,6 +495,31 @@ func (in *MetricLabel) DeepCopy() *MetricLabel {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricPushSpec) DeepCopyInto(out *MetricPushSpec) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.IsSecure != nil {
+		in, out := &in.IsSecure, &out.IsSecure
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricPushSpec.
+func (in *MetricPushSpec) DeepCopy() *MetricPushSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricPushSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetricSpec) DeepCopyInto(out *MetricSpec) {
 	*out = *in
@@ -529,6 +554,11 @@ func (in *MetricSpec) DeepCopyInto(out *MetricSpec) {
 			copy(*out, *in)
 		}
 	}
+	if in.Push != nil {
+		in, out := &in.Push, &out.Push
+		*out = new(MetricPushSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricSpec.
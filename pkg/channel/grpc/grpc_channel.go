@@ -186,6 +186,7 @@ func (g *Channel) sendJob(ctx context.Context, name string, data *anypb.Any) (*i
 
 	rsp.WithHeaders(header).
 		WithTrailers(trailer)
+	invokev1.RemoveMethodHeader(rsp.Headers())
 
 	return rsp, nil
 }
@@ -253,6 +254,7 @@ func (g *Channel) invokeMethodV1(ctx context.Context, req *invokev1.InvokeMethod
 	rsp.WithHeaders(header).
 		WithTrailers(trailer).
 		WithMessage(resp)
+	invokev1.RemoveMethodHeader(rsp.Headers())
 
 	// If the data has a type_url, set that in the object too
 	// This is necessary to support the HTTP->gRPC and gRPC->gRPC service invocation (legacy, non-proxy) paths correctly
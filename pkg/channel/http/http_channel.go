@@ -320,13 +320,14 @@ func (h *Channel) sendJob(ctx context.Context, name string, data *anypb.Any) (*i
 		Header:        rw.h,
 		ContentLength: contentLength,
 		Body:          pr,
+		Request:       channelReq,
 	}
 	resp.Status = fmt.Sprintf("%03d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
 
 	rsp, err := h.parseChannelResponse(resp)
 	if err != nil {
 		pr.Close()
-		diag.DefaultHTTPMonitoring.ClientRequestCompleted(ctx, channelReq.Method, channelReq.URL.Path, strconv.Itoa(http.StatusInternalServerError), contentLength, elapsedMs)
+		diag.DefaultHTTPMonitoring.ClientRequestCompleted(ctx, channelReq.Method, channelReq.URL.Path, strconv.Itoa(invokev1.HTTPStatusFromError(err)), contentLength, elapsedMs)
 		return nil, err
 	}
 
@@ -542,13 +543,14 @@ func (h *Channel) invokeMethodV1(ctx context.Context, req *invokev1.InvokeMethod
 		Header:        rw.h,
 		ContentLength: contentLength,
 		Body:          pr,
+		Request:       channelReq,
 	}
 	resp.Status = fmt.Sprintf("%03d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
 
 	rsp, err := h.parseChannelResponse(resp)
 	if err != nil {
 		pr.Close()
-		diag.DefaultHTTPMonitoring.ClientRequestCompleted(ctx, channelReq.Method, req.Message().GetMethod(), strconv.Itoa(http.StatusInternalServerError), contentLength, elapsedMs)
+		diag.DefaultHTTPMonitoring.ClientRequestCompleted(ctx, channelReq.Method, req.Message().GetMethod(), strconv.Itoa(invokev1.HTTPStatusFromError(err)), contentLength, elapsedMs)
 		return nil, err
 	}
 
@@ -657,7 +659,12 @@ func (h *Channel) parseChannelResponse(channelResp *http.Response) (*invokev1.In
 	// Limit response body if needed
 	var body io.ReadCloser
 	if h.maxResponseBodySize > 0 {
-		body = streamutils.LimitReadCloser(channelResp.Body, int64(h.maxResponseBodySize)<<20)
+		maxBytes := int64(h.maxResponseBodySize) << 20
+		// Reject responses declared too large upfront, rather than failing midway through the body.
+		if channelResp.ContentLength > maxBytes {
+			return nil, invokev1.ErrorPayloadTooLarge(int(channelResp.ContentLength), int(maxBytes))
+		}
+		body = streamutils.LimitReadCloser(channelResp.Body, maxBytes)
 	} else {
 		body = channelResp.Body
 	}
@@ -671,6 +678,13 @@ func (h *Channel) parseChannelResponse(channelResp *http.Response) (*invokev1.In
 		WithRawData(body).
 		WithContentType(contentType)
 
+	// Mark responses to HEAD requests, so their headers describe the body they don't have.
+	// The marker is only trusted when set here, so any sent by the app is dropped.
+	invokev1.RemoveMethodHeader(rsp.Headers())
+	if channelResp.Request != nil && channelResp.Request.Method == http.MethodHead {
+		rsp.Headers()[invokev1.MethodHeader] = invokev1.SingleValue(http.MethodHead)
+	}
+
 	return rsp, nil
 }
 
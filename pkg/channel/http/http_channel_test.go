@@ -786,6 +786,60 @@ func TestContentLength(t *testing.T) {
 	testServer.Close()
 }
 
+func TestResponseTooLarge(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(2<<20))
+		w.Write(make([]byte, 2<<20))
+	}))
+	defer testServer.Close()
+	c := Channel{
+		baseAddress:         testServer.URL,
+		client:              http.DefaultClient,
+		compStore:           compstore.New(),
+		middleware:          httpMiddleware.New().BuildPipelineFromSpec("test", nil),
+		maxResponseBodySize: 1,
+	}
+
+	req := invokev1.NewInvokeMethodRequest("method").
+		WithHTTPExtension(http.MethodGet, "")
+	defer req.Close()
+
+	_, err := c.InvokeMethod(t.Context(), req, "")
+	require.Error(t, err)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, invokev1.HTTPStatusFromError(err))
+}
+
+func TestHeadResponse(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", "42")
+		// The app cannot forge the marker of a response to a HEAD request.
+		w.Header().Set(invokev1.MethodHeader, http.MethodHead)
+	}))
+	defer testServer.Close()
+	c := Channel{
+		baseAddress: testServer.URL,
+		client:      http.DefaultClient,
+		compStore:   compstore.New(),
+		middleware:  httpMiddleware.New().BuildPipelineFromSpec("test", nil),
+	}
+
+	for _, method := range []string{http.MethodHead, http.MethodGet} {
+		t.Run(method, func(t *testing.T) {
+			req := invokev1.NewInvokeMethodRequest("method").
+				WithHTTPExtension(method, "")
+			defer req.Close()
+
+			resp, err := c.InvokeMethod(t.Context(), req, "")
+			require.NoError(t, err)
+			defer resp.Close()
+
+			assert.Equal(t, method == http.MethodHead, invokev1.IsHeadResponse(resp.Headers()))
+			assert.Equal(t, "application/json", resp.ContentType())
+		})
+	}
+}
+
 func TestAppToken(t *testing.T) {
 	t.Run("token present", func(t *testing.T) {
 		ctx := t.Context()
@@ -16,9 +16,13 @@ package pluggable
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/dapr/kit/logger"
 
+	"github.com/dapr/dapr/pkg/components"
+	diag "github.com/dapr/dapr/pkg/diagnostics"
 	proto "github.com/dapr/dapr/pkg/proto/components/v1"
 
 	"google.golang.org/grpc"
@@ -92,10 +96,48 @@ func instanceIDStreamInterceptor(instanceID string) grpc.StreamClientInterceptor
 	}
 }
 
+// componentCategories are the categories of the pluggable components, by the proto service they implement.
+//
+//nolint:nosnakecase
+var componentCategories = map[string]components.Category{
+	proto.StateStore_ServiceDesc.ServiceName:                     components.CategoryStateStore,
+	proto.TransactionalStateStore_ServiceDesc.ServiceName:        components.CategoryStateStore,
+	proto.QueriableStateStore_ServiceDesc.ServiceName:            components.CategoryStateStore,
+	proto.TransactionalStoreMultiMaxSize_ServiceDesc.ServiceName: components.CategoryStateStore,
+	proto.PubSub_ServiceDesc.ServiceName:                         components.CategoryPubSub,
+	proto.InputBinding_ServiceDesc.ServiceName:                   components.CategoryBindings,
+	proto.OutputBinding_ServiceDesc.ServiceName:                  components.CategoryBindings,
+	proto.SecretStore_ServiceDesc.ServiceName:                    components.CategorySecretStore,
+}
+
+// componentType returns the type of the pluggable component with the given name, such as "state.redis-pluggable",
+// from the category of the service of the full gRPC method. It returns the name alone for unknown services.
+func componentType(method, componentName string) string {
+	service, _, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	category, ok := componentCategories[service]
+	if !ok {
+		return componentName
+	}
+	return string(category) + "." + componentName
+}
+
+// componentUnaryInterceptor returns a grpc client unary interceptor that sets the component type and the
+// instanceID on the context, so the calls are recorded by the component metrics.
+func componentUnaryInterceptor(componentName, instanceID string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(diag.WithComponent(ctx, componentType(method, componentName), instanceID), method, req, reply, cc, opts...)
+	}
+}
+
 // socketDialer creates a dialer for the given socket.
 func socketDialer(socket string, additionalOpts ...grpc.DialOption) GRPCConnectionDialer {
+	componentName := removeExt(filepath.Base(socket))
 	return func(ctx context.Context, name string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
-		additionalOpts = append(additionalOpts, grpc.WithStreamInterceptor(instanceIDStreamInterceptor(name)), grpc.WithUnaryInterceptor(instanceIDUnaryInterceptor(name)))
+		additionalOpts = append(additionalOpts,
+			grpc.WithStreamInterceptor(instanceIDStreamInterceptor(name)),
+			grpc.WithUnaryInterceptor(instanceIDUnaryInterceptor(name)),
+			grpc.WithChainUnaryInterceptor(componentUnaryInterceptor(componentName, name), diag.DefaultGRPCMonitoring.ComponentUnaryClientInterceptor()),
+		)
 		return SocketDial(ctx, socket, append(additionalOpts, opts...)...)
 	}
 }
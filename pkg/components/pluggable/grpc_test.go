@@ -191,3 +191,11 @@ func TestGRPCConnector(t *testing.T) {
 		assert.NotContains(t, notAcceptedStatus, connector.conn.GetState())
 	})
 }
+
+func TestComponentType(t *testing.T) {
+	//nolint:nosnakecase
+	assert.Equal(t, "state.redis-pluggable", componentType("/"+proto.StateStore_ServiceDesc.ServiceName+"/Get", "redis-pluggable"))
+	//nolint:nosnakecase
+	assert.Equal(t, "pubsub.kafka-pluggable", componentType("/"+proto.PubSub_ServiceDesc.ServiceName+"/Publish", "kafka-pluggable"))
+	assert.Equal(t, "my-component", componentType("/dapr.my.service.fake/MyMethod", "my-component"))
+}
@@ -405,6 +405,38 @@ type MetricSpec struct {
 	// Latency distribution buckets. If not set, the default buckets are used.
 	LatencyDistributionBuckets *[]int        `json:"latencyDistributionBuckets,omitempty" yaml:"latencyDistributionBuckets,omitempty"`
 	Rules                      []MetricsRule `json:"rules,omitempty" yaml:"rules,omitempty"`
+	// Push configures pushing the gRPC metrics to an OTLP endpoint, in addition to exposing them for scraping.
+	Push *MetricPushSpec `json:"push,omitempty" yaml:"push,omitempty"`
+}
+
+// MetricPushSpec configures pushing the metrics to an OTLP gRPC endpoint.
+type MetricPushSpec struct {
+	EndpointAddress string `json:"endpointAddress,omitempty" yaml:"endpointAddress,omitempty"`
+	// Interval between pushes, as a duration string. Defaults to 1m
+	Interval string `json:"interval,omitempty" yaml:"interval,omitempty"`
+	// Defaults to true
+	IsSecure *bool `json:"isSecure,omitempty" yaml:"isSecure,omitempty"`
+}
+
+// GetInterval returns the interval between pushes.
+func (p MetricPushSpec) GetInterval() (time.Duration, error) {
+	if p.Interval == "" {
+		return time.Minute, nil
+	}
+	interval, err := time.ParseDuration(p.Interval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid metrics push interval '%s': %w", p.Interval, err)
+	}
+	if interval <= 0 {
+		return 0, fmt.Errorf("invalid metrics push interval '%s': must be positive", p.Interval)
+	}
+	return interval, nil
+}
+
+// GetIsSecure returns true if the connection should be secured.
+func (p MetricPushSpec) GetIsSecure() bool {
+	// Defaults to true if nil
+	return p.IsSecure == nil || *p.IsSecure
 }
 
 // GetEnabled returns true if metrics are enabled.
@@ -790,6 +790,43 @@ func TestMetricsGetHTTPExcludeVerbs(t *testing.T) {
 	})
 }
 
+func TestMetricPushSpec(t *testing.T) {
+	t.Run("interval defaults to one minute", func(t *testing.T) {
+		interval, err := MetricPushSpec{}.GetInterval()
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, interval)
+	})
+
+	t.Run("interval is parsed", func(t *testing.T) {
+		interval, err := MetricPushSpec{Interval: "15s"}.GetInterval()
+		require.NoError(t, err)
+		assert.Equal(t, 15*time.Second, interval)
+	})
+
+	t.Run("invalid interval", func(t *testing.T) {
+		_, err := MetricPushSpec{Interval: "soon"}.GetInterval()
+		require.Error(t, err)
+		_, err = MetricPushSpec{Interval: "-1s"}.GetInterval()
+		require.Error(t, err)
+	})
+
+	t.Run("secure by default", func(t *testing.T) {
+		assert.True(t, MetricPushSpec{}.GetIsSecure())
+		assert.False(t, MetricPushSpec{IsSecure: new(false)}.GetIsSecure())
+	})
+
+	t.Run("decoded from the CRD format", func(t *testing.T) {
+		var m MetricSpec
+		require.NoError(t, json.Unmarshal([]byte(`{"push":{"endpointAddress":"otel:4317","interval":"30s","isSecure":false}}`), &m))
+		require.NotNil(t, m.Push)
+		assert.Equal(t, "otel:4317", m.Push.EndpointAddress)
+		interval, err := m.Push.GetInterval()
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Second, interval)
+		assert.False(t, m.Push.GetIsSecure())
+	})
+}
+
 func TestWorkflowStateRetentionPolicyUnmarshalJSON(t *testing.T) {
 	t.Run("all fields with string durations", func(t *testing.T) {
 		data := `{"anyTerminal":"1s","completed":"2h","failed":"30m","terminated":"168h"}`
@@ -32,6 +32,7 @@ const (
 	DBConnectionStringSpanAttributeKey   = string(semconv.DBConnectionStringKey)
 	MessagingSystemSpanAttributeKey      = string(semconv.MessagingSystemKey)
 	MessagingDestinationSpanAttributeKey = string(semconv.MessagingDestinationNameKey)
+	MessagingOperationSpanAttributeKey   = string(semconv.MessagingOperationKey)
 	GrpcServiceSpanAttributeKey          = string(semconv.RPCServiceKey)
 	NetPeerNameSpanAttributeKey          = string(semconv.NetPeerNameKey)
 	RPCSystemSpanAttributeKey            = string(semconv.RPCSystemKey)
@@ -41,6 +42,13 @@ const (
 	DaprAPIProtocolSpanAttributeKey   = "dapr.protocol"
 	DaprAPIInvokeMethod               = "dapr.invoke_method"
 	DaprAPIActorTypeID                = "dapr.actor"
+	DaprSerializationMsAttributeKey   = "dapr.serialization_ms"
+	DaprBindingDirectionAttributeKey  = "dapr.binding.direction"
+
+	// RetryAttemptSpanEventName is the name of the span event added for each retry attempt.
+	RetryAttemptSpanEventName         = "dapr.retry"
+	RetryAttemptSpanAttributeKey      = "dapr.retry.attempt"
+	RetryAttemptErrorCodeAttributeKey = "dapr.retry.error_code"
 
 	OtelSpanConvHTTPRequestMethodAttributeKey = "http.request.method"
 	OtelSpanConvServerAddressAttributeKey     = "server.address"
@@ -55,6 +63,10 @@ const (
 	BindingBuildingBlockType = "bindings"
 	PubsubBuildingBlockType  = "pubsub"
 
+	// Directions of a binding invocation: input bindings trigger the app, output bindings are invoked by it.
+	BindingDirectionInput  = "input"
+	BindingDirectionOutput = "output"
+
 	DaprGRPCServiceInvocationService = "ServiceInvocation"
 	DaprGRPCDaprService              = "Dapr"
 
@@ -69,6 +81,15 @@ const (
 	TracestateHeader  = "tracestate"
 	BaggageHeader     = "baggage"
 
+	// B3 trace context headers
+	// Reference : https://github.com/openzipkin/b3-propagation
+	B3SingleHeader       = "b3"
+	B3TraceIDHeader      = "x-b3-traceid"
+	B3SpanIDHeader       = "x-b3-spanid"
+	B3ParentSpanIDHeader = "x-b3-parentspanid"
+	B3SampledHeader      = "x-b3-sampled"
+	B3FlagsHeader        = "x-b3-flags"
+
 	GRPCTraceContextKey  = "grpc-trace-bin"
 	GRPCProxyAppIDKey    = "dapr-app-id"
 	GRPCProxyCalleeIDKey = "dapr-callee-app-id"
@@ -15,18 +15,33 @@ package diagnostics
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel/attribute"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	grpcMetadata "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/dapr/dapr/pkg/api/grpc/metadata"
 	diagConsts "github.com/dapr/dapr/pkg/diagnostics/consts"
 	diagUtils "github.com/dapr/dapr/pkg/diagnostics/utils"
+	internalv1pb "github.com/dapr/dapr/pkg/proto/internals/v1"
 )
 
 // This implementation is inspired by
@@ -34,33 +49,395 @@ import (
 
 // Tag key definitions for http requests.
 var (
-	KeyServerMethod = tag.MustNewKey("grpc_server_method")
-	KeyServerStatus = tag.MustNewKey("grpc_server_status")
+	KeyServerMethod  = tag.MustNewKey("grpc_server_method")
+	KeyServerService = tag.MustNewKey("grpc_server_service")
+	KeyServerStatus  = tag.MustNewKey("grpc_server_status")
 
-	KeyClientMethod = tag.MustNewKey("grpc_client_method")
-	KeyClientStatus = tag.MustNewKey("grpc_client_status")
+	KeyClientMethod       = tag.MustNewKey("grpc_client_method")
+	KeyClientStatus       = tag.MustNewKey("grpc_client_status")
+	KeyClientWaitForReady = tag.MustNewKey("grpc_client_wait_for_ready")
+
+	KeyConnectionSecurity = tag.MustNewKey("connection_security")
+	KeyCancellationReason = tag.MustNewKey("cancellation_reason")
+	KeyRuntimeVersion     = tag.MustNewKey("dapr_version")
+	KeyTopic              = tag.MustNewKey("topic")
+	KeySDK                = tag.MustNewKey("sdk")
+	KeyErrorClass         = tag.MustNewKey("error_class")
+	KeyContentType        = tag.MustNewKey("content_type")
+	KeyErrorDetailType    = tag.MustNewKey("error_detail_type")
+	KeyRequestSizeClass   = tag.MustNewKey("request_size_class")
+	KeyPanic              = tag.MustNewKey("panic")
+	KeySampled            = tag.MustNewKey("sampled")
+	KeyDeadlineOrigin     = tag.MustNewKey("deadline_origin")
+	KeyComponentType      = tag.MustNewKey("component_type")
+	KeyComponentName      = tag.MustNewKey("component_name")
+)
+
+// runtimeVersion is the value of the KeyRuntimeVersion tag. It is empty, which omits the tag, unless set.
+var runtimeVersion string
+
+// SetRuntimeVersionTag sets the Dapr runtime version that is added as the KeyRuntimeVersion tag to the
+// server and client completed RPCs, to compare them across versions during rollouts.
+// It must be called before the gRPC metrics are initialized.
+func SetRuntimeVersionTag(version string) {
+	runtimeVersion = version
+}
+
+// Values of the KeyCancellationReason tag, returned by CancellationReason.
+const (
+	CancellationReasonClient   = "client_cancel"
+	CancellationReasonDeadline = "deadline"
+	CancellationReasonServer   = "server_cancel"
+)
+
+// otherTopic is the value of the KeyTopic tag for topics that are not in the allow list.
+const otherTopic = "other"
+
+// topicContextKey is the context key of the topic of an RPC, set by its handler with SetTopic.
+type topicContextKey struct{}
+
+// downstreamDurationContextKey is the context key of the time spent in downstream calls by an RPC,
+// in nanoseconds, reported by its handler with AddDownstreamDuration.
+type downstreamDurationContextKey struct{}
+
+// ContentTypeOther is the value of the KeyContentType tag for content types other than the known ones.
+const ContentTypeOther = "other"
+
+// knownContentTypes are the content types recorded as is in the KeyContentType tag.
+var knownContentTypes = []string{
+	internalv1pb.JSONContentType,
+	internalv1pb.ProtobufContentType,
+	internalv1pb.OctetStreamContentType,
+	internalv1pb.GRPCContentType,
+}
+
+// ContentTypeBucket returns the value of the KeyContentType tag for the content type: the known content
+// type it is, ignoring parameters and case and including suffixed gRPC types such as
+// "application/grpc+proto", or ContentTypeOther.
+func ContentTypeBucket(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	for _, known := range knownContentTypes {
+		if mediaType == known {
+			return known
+		}
+	}
+	if strings.HasPrefix(mediaType, internalv1pb.GRPCContentType+"+") {
+		return internalv1pb.GRPCContentType
+	}
+	return ContentTypeOther
+}
+
+// Values of the KeyErrorClass tag, returned by ErrorClass.
+const (
+	ErrorClassServer = "server_error"
+	ErrorClassClient = "client_error"
+)
+
+// codesByName maps the names of the gRPC codes, as returned by codes.Code.String, to the codes.
+var codesByName = func() map[string]codes.Code {
+	m := make(map[string]codes.Code, codes.Unauthenticated+1)
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		m[c.String()] = c
+	}
+	return m
+}()
+
+// IsServerError returns true if the gRPC code is a server-side fault, i.e. one whose HTTP equivalent is
+// a 5xx status, such as Internal or Unavailable, rather than a client mistake, such as InvalidArgument.
+func IsServerError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown,
+		codes.DeadlineExceeded,
+		codes.Unimplemented,
+		codes.Internal,
+		codes.Unavailable,
+		codes.DataLoss:
+		return true
+	}
+	return false
+}
+
+// ErrorClass returns the value of the KeyErrorClass tag for the gRPC code: ErrorClassServer for server
+// errors, ErrorClassClient for other errors, or an empty string for OK.
+func ErrorClass(code codes.Code) string {
+	switch {
+	case code == codes.OK:
+		return ""
+	case IsServerError(code):
+		return ErrorClassServer
+	default:
+		return ErrorClassClient
+	}
+}
+
+// SDKUnknown is the value of the KeySDK tag for clients that are not a Dapr SDK.
+const SDKUnknown = "unknown"
+
+// sdkUserAgentPrefixes maps the user-agent product of the Dapr SDKs to the value of the KeySDK tag.
+var sdkUserAgentPrefixes = map[string]string{
+	"dapr-sdk-dotnet": "dotnet",
+	"dapr-sdk-go":     "go",
+	"dapr-sdk-java":   "java",
+	"dapr-sdk-js":     "js",
+	"dapr-sdk-python": "python",
+}
+
+// SDKFromUserAgent classifies a gRPC user-agent, such as "dapr-sdk-go/v1.12.0 grpc-go/1.64.0",
+// into the name of the Dapr SDK that sent it: "dotnet", "go", "java", "js", or "python".
+// Other user-agents return SDKUnknown.
+func SDKFromUserAgent(ua string) string {
+	for _, product := range strings.Fields(strings.ToLower(ua)) {
+		name, _, _ := strings.Cut(product, "/")
+		if sdk, ok := sdkUserAgentPrefixes[name]; ok {
+			return sdk
+		}
+	}
+	return SDKUnknown
+}
+
+// Values of the KeyConnectionSecurity tag.
+const (
+	connectionSecurityMTLS      = "mtls"
+	connectionSecurityPlaintext = "plaintext"
+)
+
+// Values of the KeyRequestSizeClass tag, returned by RequestSizeClass.
+const (
+	RequestSizeClassSmall  = "small"
+	RequestSizeClassMedium = "medium"
+	RequestSizeClassLarge  = "large"
+	RequestSizeClassXLarge = "xlarge"
+)
+
+// Values of the KeyDeadlineOrigin tag, returned by DeadlineOrigin.
+const (
+	DeadlineOriginClient = "client"
+	DeadlineOriginServer = "server"
+)
+
+// Values of the KeySampled tag, returned by SampledTagValue.
+const (
+	SampledTagValueSampled   = "sampled"
+	SampledTagValueUnsampled = "unsampled"
+	SampledTagValueNone      = "none"
 )
 
 const appHealthCheckMethod = "/dapr.proto.runtime.v1.AppCallbackHealthCheck/HealthCheck"
 
+var (
+	healthCheckMethodsLock sync.RWMutex
+	// healthCheckMethods are the gRPC methods whose calls are recorded as health probes.
+	healthCheckMethods = map[string]struct{}{
+		appHealthCheckMethod: {},
+	}
+)
+
+// RegisterHealthCheckMethod registers a gRPC method, in the "/package.Service/Method" form, as a
+// health check, such as a liveness or readiness probe, so its calls are recorded as health probes.
+func RegisterHealthCheckMethod(fullMethod string) {
+	healthCheckMethodsLock.Lock()
+	defer healthCheckMethodsLock.Unlock()
+	healthCheckMethods[fullMethod] = struct{}{}
+}
+
+// isHealthCheckMethod returns true if the gRPC method is a health check.
+func isHealthCheckMethod(fullMethod string) bool {
+	healthCheckMethodsLock.RLock()
+	defer healthCheckMethodsLock.RUnlock()
+	_, ok := healthCheckMethods[fullMethod]
+	return ok
+}
+
+// SystemMethod is the method tag under which calls to system methods are recorded when collapsed.
+const SystemMethod = "__system"
+
+// SystemMethodsMode controls how calls to system methods, such as gRPC reflection and channelz, are recorded.
+type SystemMethodsMode int
+
+const (
+	// SystemMethodsRecord records calls to system methods under their own method tag.
+	SystemMethodsRecord SystemMethodsMode = iota
+	// SystemMethodsCollapse records calls to system methods under the SystemMethod tag.
+	SystemMethodsCollapse
+	// SystemMethodsExclude does not record calls to system methods.
+	SystemMethodsExclude
+)
+
+// systemServicePrefixes are the prefixes of the gRPC reflection and channelz methods.
+var systemServicePrefixes = []string{
+	"/grpc.reflection.v1alpha.ServerReflection/",
+	"/grpc.reflection.v1.ServerReflection/",
+	"/grpc.channelz.v1.Channelz/",
+}
+
+// isSystemMethod returns true if the gRPC method is a reflection or channelz method.
+func isSystemMethod(fullMethod string) bool {
+	for _, prefix := range systemServicePrefixes {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxMetricLabelValueLen is the maximum length of the label values returned by SanitizeMetricLabelValue.
+const maxMetricLabelValueLen = 256
+
+// SanitizeMetricLabelValue returns s with the characters other than ASCII letters, digits, and
+// "/", ".", "_", "-", and ":", such as spaces, replaced with "_", truncated to 256 characters. It is
+// used for the method names of proxied calls, which are not validated by Dapr, so they are safe to use
+// as label values in all exporters.
+func SanitizeMetricLabelValue(s string) string {
+	if len(s) > maxMetricLabelValueLen {
+		s = s[:maxMetricLabelValueLen]
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '/', r == '.', r == '_', r == '-', r == ':':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+// daprInternalServicePrefixes are the prefixes of the methods of the Dapr API and of the internal
+// API between Dapr sidecars.
+var daprInternalServicePrefixes = []string{
+	"/dapr.proto.runtime.v1.Dapr/",
+	"/dapr.proto.internals.v1.",
+}
+
+// IsDaprInternalMethod returns true if the gRPC method is a method of the Dapr API, such as a
+// building block call, or of the internal API between Dapr sidecars, rather than a method of an
+// app called through gRPC proxying.
+func IsDaprInternalMethod(fullMethod string) bool {
+	for _, prefix := range daprInternalServicePrefixes {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// errServerHandlerTimeout is the cause of the cancellation of handlers exceeding the server handler timeout.
+var errServerHandlerTimeout = errors.New("server handler timeout exceeded")
+
+// serverMethodLatencyViewName is the name of the optional per-method server latency view.
+const serverMethodLatencyViewName = "grpc.io/server/method_latency"
+
+// methodLatencyDistribution buckets server latencies per method, in milliseconds.
+// OpenCensus has no summary aggregation, so p50/p95/p99 are derived from a
+// distribution instead; the buckets are denser than the default latency
+// distribution in the sub-second range where most RPCs complete, which keeps
+// the interpolated percentiles actionable. Distributions are also preferable to
+// summaries because they can be aggregated across instances.
+var methodLatencyDistribution = view.Distribution(0.5, 1, 2, 3, 5, 7.5, 10, 15, 20, 30, 50, 75, 100, 150, 200, 300, 500, 750, 1_000, 2_500, 5_000, 10_000)
+
+// compressionRatioDistribution buckets the ratios of uncompressed to compressed payload sizes.
+var compressionRatioDistribution = view.Distribution(0.5, 1, 1.25, 1.5, 2, 3, 4, 5, 7.5, 10, 20, 50)
+
+// metadataConversionDistribution buckets metadata conversion latencies, in milliseconds.
+// Conversions take microseconds, well below the first bucket of the latency distribution.
+var metadataConversionDistribution = view.Distribution(0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10)
+
 type grpcMetrics struct {
 	serverReceivedBytes *stats.Int64Measure
 	serverSentBytes     *stats.Int64Measure
 	serverLatency       *stats.Float64Measure
+	serverLatencySec    *stats.Float64Measure
 	serverCompletedRpcs *stats.Int64Measure
+	serverQueueDelay    *stats.Float64Measure
+	serverMessageSize   *stats.Int64Measure
+	serverTimeouts      *stats.Int64Measure
+	serverCanceledRpcs  *stats.Int64Measure
+	// streamHalfCloseToCompletion is the time from the client half-closing a stream to the handler returning.
+	streamHalfCloseToCompletion *stats.Float64Measure
+	// serverSelfLatency is the server latency excluding the time spent in downstream calls.
+	serverSelfLatency *stats.Float64Measure
+
+	serializationLatency      *stats.Float64Measure
+	metadataConversionLatency *stats.Float64Measure
+
+	clientSentBytes           *stats.Int64Measure
+	clientReceivedBytes       *stats.Int64Measure
+	clientRoundtripLatency    *stats.Float64Measure
+	clientRoundtripLatencySec *stats.Float64Measure
+	clientCompletedRpcs       *stats.Int64Measure
+	clientConnectionsCreated  *stats.Int64Measure
+	clientConnectionsReused   *stats.Int64Measure
 
-	clientSentBytes        *stats.Int64Measure
-	clientReceivedBytes    *stats.Int64Measure
-	clientRoundtripLatency *stats.Float64Measure
-	clientCompletedRpcs    *stats.Int64Measure
+	componentRoundtripLatency *stats.Float64Measure
+	componentCompletedRpcs    *stats.Int64Measure
 
-	healthProbeCompletedCount   *stats.Int64Measure
-	healthProbeRoundtripLatency *stats.Float64Measure
+	payloadCompressionRatio *stats.Float64Measure
+	errorDetailsByType      *stats.Int64Measure
+	headersTrimmed          *stats.Int64Measure
+
+	healthProbeCompletedCount      *stats.Int64Measure
+	healthProbeRoundtripLatency    *stats.Float64Measure
+	healthProbeRoundtripLatencySec *stats.Float64Measure
 
 	appID   string
 	enabled bool
 
-	meter stats.Recorder
+	// secondsLatency enables the latency views in seconds; millisecondsLatency enables the latency views in milliseconds.
+	secondsLatency      bool
+	millisecondsLatency bool
+	// methodLatencyView enables the per-method server latency view.
+	methodLatencyView bool
+	// selfLatency enables recording the server latency excluding the time spent in downstream calls.
+	selfLatency bool
+	// serializationMetrics enables recording the time spent converting messages between Protobuf and JSON.
+	serializationMetrics bool
+	// recordOnlyWhenSampled records the per-method latency and size measures only for RPCs in a sampled trace.
+	recordOnlyWhenSampled bool
+	// metadataConversionMetrics enables recording the time spent converting internal metadata.
+	metadataConversionMetrics bool
+	// treatCanceledAsError records RPCs canceled by the client in the error code metrics.
+	treatCanceledAsError bool
+	// slowRequestThreshold is the server latency above which a request is logged. Zero disables logging.
+	slowRequestThreshold time.Duration
+	// serverHandlerTimeout is the maximum duration of unary handlers. Zero disables the timeout.
+	serverHandlerTimeout time.Duration
+	// completedRpcsMethodTag and completedRpcsServiceTag enable the KeyServerMethod and KeyServerService
+	// tags on the server completed RPCs view.
+	completedRpcsMethodTag  bool
+	completedRpcsServiceTag bool
+	// connectionSecurityTag enables the KeyConnectionSecurity tag on server latency and completed RPCs.
+	connectionSecurityTag bool
+	// requestSizeClassTag enables the KeyRequestSizeClass tag on server latency.
+	requestSizeClassTag bool
+	// waitForReadyTag enables the KeyClientWaitForReady tag on client latency and completed RPCs.
+	waitForReadyTag bool
+	// systemMethods controls how calls to reflection and channelz methods are recorded.
+	systemMethods SystemMethodsMode
+	// sdkTag enables the KeySDK tag on server completed RPCs.
+	sdkTag bool
+	// sampledTag enables the KeySampled tag on server and client completed RPCs.
+	sampledTag bool
+	// errorClassTag enables the KeyErrorClass tag on server completed RPCs.
+	errorClassTag bool
+	// panicTag enables the KeyPanic tag on server completed RPCs.
+	panicTag bool
+	// deadlineOriginTag enables the KeyDeadlineOrigin tag on server completed RPCs.
+	deadlineOriginTag bool
+	// topicAllowList are the topics recorded in the KeyTopic tag on server completed RPCs.
+	// If empty, the tag is disabled.
+	topicAllowList map[string]struct{}
+	// pushOptions configure pushing the metrics to an OTLP endpoint; pusher pushes them once initialized.
+	pushOptions PushExportOptions
+	pusher      *metricsPusher
+
+	// constantTags are added to every recorded measurement; constantTagKeys are their keys.
+	constantTags    []tag.Mutator
+	constantTagKeys []tag.Key
+
+	meter view.Meter
+	views []*view.View
 }
 
 func newGRPCMetrics() *grpcMetrics {
@@ -77,10 +454,47 @@ func newGRPCMetrics() *grpcMetrics {
 			"grpc.io/server/server_latency",
 			"Time between first byte of request received to last byte of response sent, or terminal error.",
 			stats.UnitMilliseconds),
+		serverLatencySec: stats.Float64(
+			"grpc.io/server/server_latency_seconds",
+			"Time between first byte of request received to last byte of response sent, or terminal error.",
+			"s"),
 		serverCompletedRpcs: stats.Int64(
 			"grpc.io/server/completed_rpcs",
 			"Distribution of bytes sent per RPC, by method.",
 			stats.UnitDimensionless),
+		serverMessageSize: stats.Int64(
+			"grpc.io/server/message_size_bytes",
+			"Distribution of the size of individual messages received and sent on streaming RPCs.",
+			stats.UnitBytes),
+		serverTimeouts: stats.Int64(
+			"grpc.io/server/handler_timeouts",
+			"Count of RPCs whose handler exceeded the server handler timeout.",
+			stats.UnitDimensionless),
+		serverCanceledRpcs: stats.Int64(
+			"grpc.io/server/canceled_rpcs",
+			"Count of canceled RPCs by method and cancellation reason.",
+			stats.UnitDimensionless),
+		serverQueueDelay: stats.Float64(
+			"grpc.io/server/queue_delay",
+			"Time between the request arriving at the server and the handler being invoked.",
+			stats.UnitMilliseconds),
+		streamHalfCloseToCompletion: stats.Float64(
+			"grpc.io/server/stream_half_close_to_completion",
+			"Time between the client half-closing a stream and the server completing it.",
+			stats.UnitMilliseconds),
+		serverSelfLatency: stats.Float64(
+			"grpc.io/server/self_latency",
+			"Server latency excluding the time spent in downstream calls, such as to the invoked app.",
+			stats.UnitMilliseconds),
+
+		serializationLatency: stats.Float64(
+			"grpc.io/serialization/latency",
+			"Time spent converting messages between Protobuf and JSON.",
+			stats.UnitMilliseconds),
+		metadataConversionLatency: stats.Float64(
+			"grpc.io/metadata/conversion_latency",
+			"Time spent converting internal metadata to gRPC metadata or HTTP headers.",
+			stats.UnitMilliseconds),
 
 		clientSentBytes: stats.Int64(
 			"grpc.io/client/sent_bytes_per_rpc",
@@ -94,10 +508,44 @@ func newGRPCMetrics() *grpcMetrics {
 			"grpc.io/client/roundtrip_latency",
 			"Time between first byte of request sent to last byte of response received, or terminal error.",
 			stats.UnitMilliseconds),
+		clientRoundtripLatencySec: stats.Float64(
+			"grpc.io/client/roundtrip_latency_seconds",
+			"Time between first byte of request sent to last byte of response received, or terminal error.",
+			"s"),
 		clientCompletedRpcs: stats.Int64(
 			"grpc.io/client/completed_rpcs",
 			"Count of RPCs by method and status.",
 			stats.UnitDimensionless),
+		clientConnectionsCreated: stats.Int64(
+			"grpc.io/client/connections_created",
+			"Count of client connections created.",
+			stats.UnitDimensionless),
+		clientConnectionsReused: stats.Int64(
+			"grpc.io/client/connections_reused",
+			"Count of RPCs sent on a client connection that already carried an earlier RPC.",
+			stats.UnitDimensionless),
+
+		componentRoundtripLatency: stats.Float64(
+			"grpc.io/component/roundtrip_latency",
+			"Time between first byte of request sent to a pluggable component to last byte of response received, or terminal error.",
+			stats.UnitMilliseconds),
+		componentCompletedRpcs: stats.Int64(
+			"grpc.io/component/completed_rpcs",
+			"Count of RPCs to pluggable components by component, method and status.",
+			stats.UnitDimensionless),
+
+		payloadCompressionRatio: stats.Float64(
+			"grpc.io/payload/compression_ratio",
+			"Ratio of the uncompressed to the compressed size of payloads, by content type.",
+			stats.UnitDimensionless),
+		errorDetailsByType: stats.Int64(
+			"grpc.io/error_details/count",
+			"Count of the details attached to gRPC status errors, by the type URL of the detail.",
+			stats.UnitDimensionless),
+		headersTrimmed: stats.Int64(
+			"grpc.io/headers/trimmed_count",
+			"Count of the HTTP header sets trimmed because they exceeded the maximum header size.",
+			stats.UnitDimensionless),
 
 		healthProbeCompletedCount: stats.Int64(
 			"grpc.io/healthprobes/completed_count",
@@ -107,28 +555,756 @@ func newGRPCMetrics() *grpcMetrics {
 			"grpc.io/healthprobes/roundtrip_latency",
 			"Time between first byte of health probes sent to last byte of response received, or terminal error",
 			stats.UnitMilliseconds),
+		healthProbeRoundtripLatencySec: stats.Float64(
+			"grpc.io/healthprobes/roundtrip_latency_seconds",
+			"Time between first byte of health probes sent to last byte of response received, or terminal error",
+			"s"),
 
-		enabled: false,
+		millisecondsLatency:    true,
+		completedRpcsMethodTag: true,
+		treatCanceledAsError:   true,
+		enabled:                false,
 	}
 }
 
 func (g *grpcMetrics) Init(meter view.Meter, appID string, latencyDistribution *view.Aggregation) error {
+	if g == nil {
+		return nil
+	}
+
 	g.appID = appID
-	g.enabled = true
 	g.meter = meter
 
-	return meter.Register(
+	serverCompletedRpcsKeys := withRuntimeVersionTagKey(g.completedRpcsTagKeys()...)
+	if len(g.topicAllowList) > 0 {
+		serverCompletedRpcsKeys = append(serverCompletedRpcsKeys, KeyTopic)
+	}
+	if g.sdkTag {
+		serverCompletedRpcsKeys = append(serverCompletedRpcsKeys, KeySDK)
+	}
+	if g.sampledTag {
+		serverCompletedRpcsKeys = append(serverCompletedRpcsKeys, KeySampled)
+	}
+	if g.errorClassTag {
+		serverCompletedRpcsKeys = append(serverCompletedRpcsKeys, KeyErrorClass)
+	}
+	if g.panicTag {
+		serverCompletedRpcsKeys = append(serverCompletedRpcsKeys, KeyPanic)
+	}
+	if g.deadlineOriginTag {
+		serverCompletedRpcsKeys = append(serverCompletedRpcsKeys, KeyDeadlineOrigin)
+	}
+	serverLatencyViews := g.latencyViews(g.serverLatency, g.serverLatencySec, []tag.Key{appIDKey, KeyServerMethod, KeyServerStatus}, latencyDistribution)
+	if g.requestSizeClassTag {
+		serverLatencyViews = diagUtils.AddNewTagKey(serverLatencyViews, &KeyRequestSizeClass)
+	}
+	serverViews := append(
+		serverLatencyViews,
+		diagUtils.NewMeasureView(g.serverCompletedRpcs, serverCompletedRpcsKeys, view.Count()),
+	)
+	if g.connectionSecurityTag {
+		serverViews = diagUtils.AddNewTagKey(serverViews, &KeyConnectionSecurity)
+	}
+
+	clientCompletedRpcsKeys := withRuntimeVersionTagKey(appIDKey, KeyClientMethod, KeyClientStatus)
+	if g.sampledTag {
+		clientCompletedRpcsKeys = append(clientCompletedRpcsKeys, KeySampled)
+	}
+	clientViews := append(
+		g.latencyViews(g.clientRoundtripLatency, g.clientRoundtripLatencySec, []tag.Key{appIDKey, KeyClientMethod, KeyClientStatus}, latencyDistribution),
+		diagUtils.NewMeasureView(g.clientCompletedRpcs, clientCompletedRpcsKeys, view.Count()),
+	)
+	if g.waitForReadyTag {
+		clientViews = diagUtils.AddNewTagKey(clientViews, &KeyClientWaitForReady)
+	}
+
+	views := append(serverViews,
 		diagUtils.NewMeasureView(g.serverReceivedBytes, []tag.Key{appIDKey, KeyServerMethod}, defaultSizeDistribution),
 		diagUtils.NewMeasureView(g.serverSentBytes, []tag.Key{appIDKey, KeyServerMethod}, defaultSizeDistribution),
-		diagUtils.NewMeasureView(g.serverLatency, []tag.Key{appIDKey, KeyServerMethod, KeyServerStatus}, latencyDistribution),
-		diagUtils.NewMeasureView(g.serverCompletedRpcs, []tag.Key{appIDKey, KeyServerMethod, KeyServerStatus}, view.Count()),
+		diagUtils.NewMeasureView(g.serverQueueDelay, []tag.Key{appIDKey, KeyServerMethod}, latencyDistribution),
+		diagUtils.NewMeasureView(g.serverMessageSize, []tag.Key{appIDKey, KeyServerMethod}, defaultSizeDistribution),
+		diagUtils.NewMeasureView(g.serverTimeouts, []tag.Key{appIDKey, KeyServerMethod}, view.Count()),
+		diagUtils.NewMeasureView(g.serverCanceledRpcs, []tag.Key{appIDKey, KeyServerMethod, KeyCancellationReason}, view.Count()),
+		diagUtils.NewMeasureView(g.streamHalfCloseToCompletion, []tag.Key{appIDKey, KeyServerMethod}, latencyDistribution),
 		diagUtils.NewMeasureView(g.clientSentBytes, []tag.Key{appIDKey, KeyClientMethod}, defaultSizeDistribution),
 		diagUtils.NewMeasureView(g.clientReceivedBytes, []tag.Key{appIDKey, KeyClientMethod}, defaultSizeDistribution),
-		diagUtils.NewMeasureView(g.clientRoundtripLatency, []tag.Key{appIDKey, KeyClientMethod, KeyClientStatus}, latencyDistribution),
-		diagUtils.NewMeasureView(g.clientCompletedRpcs, []tag.Key{appIDKey, KeyClientMethod, KeyClientStatus}, view.Count()),
-		diagUtils.NewMeasureView(g.healthProbeRoundtripLatency, []tag.Key{appIDKey, KeyClientStatus}, latencyDistribution),
 		diagUtils.NewMeasureView(g.healthProbeCompletedCount, []tag.Key{appIDKey, KeyClientStatus}, view.Count()),
+		diagUtils.NewMeasureView(g.clientConnectionsCreated, []tag.Key{appIDKey}, view.Count()),
+		diagUtils.NewMeasureView(g.clientConnectionsReused, []tag.Key{appIDKey}, view.Count()),
+		diagUtils.NewMeasureView(g.componentRoundtripLatency, []tag.Key{appIDKey, KeyComponentType, KeyComponentName, KeyClientMethod, KeyClientStatus}, latencyDistribution),
+		diagUtils.NewMeasureView(g.componentCompletedRpcs, []tag.Key{appIDKey, KeyComponentType, KeyComponentName, KeyClientMethod, KeyClientStatus}, view.Count()),
+		diagUtils.NewMeasureView(g.payloadCompressionRatio, []tag.Key{appIDKey, KeyContentType}, compressionRatioDistribution),
+		diagUtils.NewMeasureView(g.errorDetailsByType, []tag.Key{appIDKey, KeyErrorDetailType}, view.Count()),
+		diagUtils.NewMeasureView(g.headersTrimmed, []tag.Key{appIDKey}, view.Count()),
 	)
+	views = append(views, clientViews...)
+	views = append(views, g.latencyViews(g.healthProbeRoundtripLatency, g.healthProbeRoundtripLatencySec, []tag.Key{appIDKey, KeyClientStatus}, latencyDistribution)...)
+
+	if g.selfLatency {
+		views = append(views, diagUtils.NewMeasureView(g.serverSelfLatency, []tag.Key{appIDKey, KeyServerMethod}, latencyDistribution))
+	}
+	if g.methodLatencyView {
+		views = append(views, &view.View{
+			Name:        serverMethodLatencyViewName,
+			Description: "Distribution of server latency per method, for computing percentiles.",
+			Measure:     g.serverLatency,
+			TagKeys:     []tag.Key{appIDKey, KeyServerMethod},
+			Aggregation: methodLatencyDistribution,
+		})
+	}
+
+	if g.serializationMetrics {
+		views = append(views, diagUtils.NewMeasureView(g.serializationLatency, []tag.Key{appIDKey, operationKey}, latencyDistribution))
+	}
+	if g.metadataConversionMetrics {
+		views = append(views, diagUtils.NewMeasureView(g.metadataConversionLatency, []tag.Key{appIDKey, operationKey}, metadataConversionDistribution))
+	}
+
+	for i := range g.constantTagKeys {
+		views = diagUtils.AddNewTagKey(views, &g.constantTagKeys[i])
+	}
+
+	if g.pushOptions.Endpoint != "" && g.pushOptions.Interval <= 0 {
+		return fmt.Errorf("invalid metrics push interval: %v", g.pushOptions.Interval)
+	}
+
+	// Register the views one at a time so that, if one fails, the views registered
+	// so far can be rolled back instead of leaving the metrics half-initialized.
+	for i, v := range views {
+		if err := meter.Register(v); err != nil {
+			meter.Unregister(views[:i]...)
+			g.enabled = false
+			g.views = nil
+			return fmt.Errorf("failed to register view %s: %w", v.Name, err)
+		}
+	}
+
+	if g.pushOptions.Endpoint != "" {
+		provider, err := newMetricsPusher(g.pushOptions, appID)
+		if err != nil {
+			meter.Unregister(views...)
+			return err
+		}
+		g.pusher = &metricsPusher{provider: provider}
+	}
+
+	g.views = views
+	g.enabled = true
+	return nil
+}
+
+// completedRpcsTagKeys returns the tag keys of the server completed RPCs view.
+func (g *grpcMetrics) completedRpcsTagKeys() []tag.Key {
+	keys := []tag.Key{appIDKey}
+	if g.completedRpcsMethodTag {
+		keys = append(keys, KeyServerMethod)
+	}
+	if g.completedRpcsServiceTag {
+		keys = append(keys, KeyServerService)
+	}
+	return append(keys, KeyServerStatus)
+}
+
+// withRuntimeVersionTagKey returns the tag keys, plus KeyRuntimeVersion if the runtime version is set.
+func withRuntimeVersionTagKey(keys ...tag.Key) []tag.Key {
+	if runtimeVersion == "" {
+		return keys
+	}
+	return append(keys, KeyRuntimeVersion)
+}
+
+// SetCompletedRpcsTags sets whether the server completed RPCs are tagged by full method, by service
+// (the full method without the method name), or both, to roll up high-cardinality methods per service.
+// By default, they are tagged by full method only. It must be called before Init.
+func (g *grpcMetrics) SetCompletedRpcsTags(method, service bool) {
+	if g == nil {
+		return
+	}
+	g.completedRpcsMethodTag = method
+	g.completedRpcsServiceTag = service
+}
+
+// completedRpcsService returns the value of the KeyServerService tag for the method,
+// or an empty string, which omits the tag, if the tag is disabled.
+func (g *grpcMetrics) completedRpcsService(method string) string {
+	if !g.completedRpcsServiceTag {
+		return ""
+	}
+	return serviceFromFullMethod(method)
+}
+
+// serviceFromFullMethod returns the service of a gRPC method in the "/package.Service/Method" form,
+// or an empty string if the method is malformed.
+func serviceFromFullMethod(fullMethod string) string {
+	i := strings.LastIndexByte(fullMethod, '/')
+	if i <= 0 {
+		return ""
+	}
+	return strings.TrimPrefix(fullMethod[:i], "/")
+}
+
+// latencyViews returns the views of a latency measure in milliseconds, and of its equivalent in seconds,
+// as enabled.
+func (g *grpcMetrics) latencyViews(ms, sec *stats.Float64Measure, keys []tag.Key, distribution *view.Aggregation) []*view.View {
+	views := make([]*view.View, 0, 2)
+	if g.millisecondsLatency {
+		views = append(views, diagUtils.NewMeasureView(ms, keys, distribution))
+	}
+	if g.secondsLatency {
+		buckets := make([]float64, len(distribution.Buckets))
+		for i, b := range distribution.Buckets {
+			buckets[i] = b / 1000
+		}
+		views = append(views, diagUtils.NewMeasureView(sec, keys, view.Distribution(buckets...)))
+	}
+	return views
+}
+
+// latencyMeasurements returns the recording option for the elapsed milliseconds of a latency measure
+// and, if enabled, for the elapsed seconds of its equivalent in seconds.
+func (g *grpcMetrics) latencyMeasurements(ms, sec *stats.Float64Measure, elapsed float64) stats.Options {
+	measurements := []stats.Measurement{ms.M(elapsed)}
+	if g.secondsLatency {
+		measurements = append(measurements, sec.M(elapsed/1000))
+	}
+	return stats.WithMeasurements(measurements...)
+}
+
+// EnableSecondsLatencyViews registers the latency views in seconds, with "_seconds" suffixed names, for
+// compliance with OpenMetrics. The views in milliseconds are kept for backwards compatibility unless
+// keepMilliseconds is false. It must be called before Init.
+func (g *grpcMetrics) EnableSecondsLatencyViews(keepMilliseconds bool) {
+	if g == nil {
+		return
+	}
+	g.secondsLatency = true
+	g.millisecondsLatency = keepMilliseconds
+}
+
+// SetConstantTags sets static tags, such as the cluster or region, that are added to every gRPC metric.
+// It must be called before Init.
+func (g *grpcMetrics) SetConstantTags(tags map[string]string) error {
+	if g == nil {
+		return nil
+	}
+
+	names := slices.Sorted(maps.Keys(tags))
+	keys := make([]tag.Key, len(names))
+	mutators := make([]tag.Mutator, len(names))
+	for i, name := range names {
+		key, err := tag.NewKey(name)
+		if err != nil {
+			return fmt.Errorf("invalid constant tag %q: %w", name, err)
+		}
+		keys[i] = key
+		mutators[i] = tag.Upsert(key, tags[name])
+	}
+
+	g.constantTagKeys = keys
+	g.constantTags = mutators
+	return nil
+}
+
+// withTags returns the recording option for the tag key and value pairs, plus the constant tags.
+func (g *grpcMetrics) withTags(name string, opts ...any) stats.Options {
+	return stats.WithTags(append(diagUtils.WithTags(name, opts...), g.constantTags...)...)
+}
+
+// Flush waits until all the measurements recorded so far have been aggregated by the meter,
+// so they are visible to pull-based exporters, such as Prometheus, before a short-lived process exits.
+// If pushing is enabled with SetPushExport, the metrics are then pushed without waiting for the next
+// interval. Other exporters registered on the meter, that stream data on each reporting period, are
+// not flushed: for those, Flush is a no-op beyond waiting for the pending measurements.
+func (g *grpcMetrics) Flush(ctx context.Context) error {
+	if !g.IsEnabled() {
+		return nil
+	}
+
+	if err := g.aggregate(ctx); err != nil {
+		return err
+	}
+	return g.pusher.forceFlush(ctx)
+}
+
+// aggregate waits until all the measurements recorded so far have been aggregated by the meter.
+func (g *grpcMetrics) aggregate(ctx context.Context) error {
+	if !g.IsEnabled() || len(g.views) == 0 {
+		return nil
+	}
+
+	// The meter processes recordings and data retrievals in order, so retrieving
+	// the data of a view returns once all the pending recordings are aggregated.
+	done := make(chan error, 1)
+	go func() {
+		_, err := g.meter.RetrieveData(g.views[0].Name)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// EnableSelfLatency enables recording the latency of unary RPCs excluding the time spent in downstream
+// calls, which handlers report with AddDownstreamDuration, to isolate the processing cost of Dapr from
+// that of the invoked app. It must be called before Init.
+func (g *grpcMetrics) EnableSelfLatency() {
+	if g == nil {
+		return
+	}
+	g.selfLatency = true
+}
+
+// withDownstreamDuration returns a context in which the handler of an RPC can report the time spent in
+// downstream calls with AddDownstreamDuration, if the self latency is enabled.
+func (g *grpcMetrics) withDownstreamDuration(ctx context.Context) context.Context {
+	if !g.selfLatency {
+		return ctx
+	}
+	return context.WithValue(ctx, downstreamDurationContextKey{}, new(atomic.Int64))
+}
+
+// AddDownstreamDuration adds d to the time spent in downstream calls by the RPC whose handler is invoked
+// with ctx, which is excluded from its self latency. It is a no-op if the self latency is disabled or ctx
+// is not the one of an RPC.
+func (g *grpcMetrics) AddDownstreamDuration(ctx context.Context, d time.Duration) {
+	if g == nil {
+		return
+	}
+	if p, ok := ctx.Value(downstreamDurationContextKey{}).(*atomic.Int64); ok {
+		p.Add(int64(d))
+	}
+}
+
+// EnableMethodLatencyView enables an additional server latency view tagged by method
+// only, with buckets tuned for computing per-method percentiles. It must be called before Init.
+func (g *grpcMetrics) EnableMethodLatencyView() {
+	if g == nil {
+		return
+	}
+	g.methodLatencyView = true
+}
+
+// EnableSerializationMetrics enables recording the time spent converting messages between
+// Protobuf and JSON, as a metric and as a span attribute. It must be called before Init.
+func (g *grpcMetrics) EnableSerializationMetrics() {
+	if g == nil {
+		return
+	}
+	g.serializationMetrics = true
+}
+
+// SerializationMetricsEnabled returns true if the serialization time is recorded.
+func (g *grpcMetrics) SerializationMetricsEnabled() bool {
+	return g.IsEnabled() && g.serializationMetrics
+}
+
+// EnableMetadataConversionMetrics enables recording the time spent converting internal metadata to
+// gRPC metadata or HTTP headers. It is meant for debugging and must be called before Init.
+func (g *grpcMetrics) EnableMetadataConversionMetrics() {
+	if g == nil {
+		return
+	}
+	g.metadataConversionMetrics = true
+}
+
+// MetadataConversionMetricsEnabled returns true if the metadata conversion time is recorded.
+func (g *grpcMetrics) MetadataConversionMetricsEnabled() bool {
+	return g.IsEnabled() && g.metadataConversionMetrics
+}
+
+// EnableConnectionSecurityTag adds the KeyConnectionSecurity tag ("mtls" or "plaintext") to the
+// server latency and completed RPCs views. It must be called before Init.
+func (g *grpcMetrics) EnableConnectionSecurityTag() {
+	if g == nil {
+		return
+	}
+	g.connectionSecurityTag = true
+}
+
+// EnableRequestSizeClassTag adds the KeyRequestSizeClass tag, the RequestSizeClass of the request
+// payload, to the server latency views of unary calls, to correlate latency with payload size.
+// It is disabled by default as it multiplies the cardinality of the views. It must be called before Init.
+func (g *grpcMetrics) EnableRequestSizeClassTag() {
+	if g == nil {
+		return
+	}
+	g.requestSizeClassTag = true
+}
+
+// RequestSizeClass returns the value of the KeyRequestSizeClass tag for a request payload of size bytes:
+// RequestSizeClassSmall below 1KB, RequestSizeClassMedium below 64KB, RequestSizeClassLarge below 1MB,
+// and RequestSizeClassXLarge otherwise.
+func RequestSizeClass(size int64) string {
+	switch {
+	case size < 1<<10:
+		return RequestSizeClassSmall
+	case size < 64<<10:
+		return RequestSizeClassMedium
+	case size < 1<<20:
+		return RequestSizeClassLarge
+	default:
+		return RequestSizeClassXLarge
+	}
+}
+
+// requestSizeClass returns the value of the KeyRequestSizeClass tag for a request payload of size bytes,
+// or an empty string, which omits the tag, if the tag is disabled.
+func (g *grpcMetrics) requestSizeClass(size int64) string {
+	if !g.requestSizeClassTag {
+		return ""
+	}
+	return RequestSizeClass(size)
+}
+
+// EnableWaitForReadyTag adds the KeyClientWaitForReady tag ("true" or "false"), reflecting whether the
+// grpc.WaitForReady call option was set, to the client latency and completed RPCs views of unary calls.
+// It must be called before Init.
+func (g *grpcMetrics) EnableWaitForReadyTag() {
+	if g == nil {
+		return
+	}
+	g.waitForReadyTag = true
+}
+
+// waitForReady returns the value of the KeyClientWaitForReady tag for the call options,
+// or an empty string, which omits the tag, if the tag is disabled.
+func (g *grpcMetrics) waitForReady(opts []grpc.CallOption) string {
+	if !g.waitForReadyTag {
+		return ""
+	}
+
+	waitForReady := false
+	for _, opt := range opts {
+		// The last option wins, as when the call options are applied.
+		if o, ok := opt.(grpc.FailFastCallOption); ok {
+			waitForReady = !o.FailFast
+		}
+	}
+	return strconv.FormatBool(waitForReady)
+}
+
+// SetSystemMethods sets how the interceptors record calls to system methods, such as gRPC reflection
+// and channelz, to keep operational tooling traffic out of the per-method metrics.
+// It must be called before Init.
+func (g *grpcMetrics) SetSystemMethods(mode SystemMethodsMode) {
+	if g == nil {
+		return
+	}
+	g.systemMethods = mode
+}
+
+// recordedMethod returns the method tag under which calls to the gRPC method are recorded,
+// and false if they are not recorded.
+func (g *grpcMetrics) recordedMethod(fullMethod string) (string, bool) {
+	if g.systemMethods == SystemMethodsRecord || !isSystemMethod(fullMethod) {
+		return fullMethod, true
+	}
+	if g.systemMethods == SystemMethodsExclude {
+		return "", false
+	}
+	return SystemMethod, true
+}
+
+// SetTopicAllowList enables the KeyTopic tag on the server completed RPCs, for unary RPCs whose handler
+// sets their topic with SetTopic, such as publishing events. Topics not in the allow list are recorded as
+// "other", to bound the cardinality. An empty allow list disables the tag. It must be called before Init.
+func (g *grpcMetrics) SetTopicAllowList(topics []string) {
+	if g == nil {
+		return
+	}
+	g.topicAllowList = make(map[string]struct{}, len(topics))
+	for _, topic := range topics {
+		g.topicAllowList[topic] = struct{}{}
+	}
+}
+
+// withTopic returns a context in which the handler of an RPC can set its topic with SetTopic,
+// if the KeyTopic tag is enabled.
+func (g *grpcMetrics) withTopic(ctx context.Context) context.Context {
+	if len(g.topicAllowList) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, topicContextKey{}, new(string))
+}
+
+// SetTopic sets the topic of the RPC whose handler is invoked with ctx, to record it in the KeyTopic tag
+// of the server completed RPCs. It is a no-op if the tag is disabled or ctx is not the one of an RPC.
+func (g *grpcMetrics) SetTopic(ctx context.Context, topic string) {
+	if g == nil {
+		return
+	}
+	if p, ok := ctx.Value(topicContextKey{}).(*string); ok {
+		*p = topic
+	}
+}
+
+// topic returns the value of the KeyTopic tag for the RPC in ctx, or an empty string,
+// which omits the tag, if the tag is disabled or the handler did not set a topic.
+func (g *grpcMetrics) topic(ctx context.Context) string {
+	p, ok := ctx.Value(topicContextKey{}).(*string)
+	if !ok || *p == "" {
+		return ""
+	}
+	if _, ok := g.topicAllowList[*p]; ok {
+		return *p
+	}
+	return otherTopic
+}
+
+// EnableSDKTag adds the KeySDK tag, the Dapr SDK that sent the RPC as classified by SDKFromUserAgent,
+// to the server completed RPCs view. It must be called before Init.
+func (g *grpcMetrics) EnableSDKTag() {
+	if g == nil {
+		return
+	}
+	g.sdkTag = true
+}
+
+// EnableSampledTag adds the KeySampled tag, whether the RPC is part of a sampled trace as returned by
+// SampledTagValue, to the server and client completed RPCs views, to analyze the sampling coverage.
+// It must be called before Init.
+func (g *grpcMetrics) EnableSampledTag() {
+	if g == nil {
+		return
+	}
+	g.sampledTag = true
+}
+
+// SampledTagValue returns the value of the KeySampled tag for the span context in ctx:
+// SampledTagValueSampled or SampledTagValueUnsampled, per its trace flags, or SampledTagValueNone
+// if there is no valid span context.
+func SampledTagValue(ctx context.Context) string {
+	sc := diagUtils.SpanFromContext(ctx).SpanContext()
+	switch {
+	case !sc.IsValid():
+		return SampledTagValueNone
+	case sc.IsSampled():
+		return SampledTagValueSampled
+	default:
+		return SampledTagValueUnsampled
+	}
+}
+
+// sampled returns the value of the KeySampled tag for ctx, or an empty string, which omits the tag,
+// if the tag is disabled.
+func (g *grpcMetrics) sampled(ctx context.Context) string {
+	if !g.sampledTag {
+		return ""
+	}
+	return SampledTagValue(ctx)
+}
+
+// EnableErrorClassTag adds the KeyErrorClass tag, which splits failed RPCs into server and client
+// errors as classified by ErrorClass, to the server completed RPCs view, so error-rate alerts can
+// ignore bad client input. It must be called before Init.
+func (g *grpcMetrics) EnableErrorClassTag() {
+	if g == nil {
+		return
+	}
+	g.errorClassTag = true
+}
+
+// errorClass returns the value of the KeyErrorClass tag for the status of an RPC,
+// or an empty string, which omits the tag, if the tag is disabled or the status is unknown.
+func (g *grpcMetrics) errorClass(status string) string {
+	if !g.errorClassTag {
+		return ""
+	}
+	code, ok := codesByName[status]
+	if !ok {
+		return ""
+	}
+	return ErrorClass(code)
+}
+
+// DeadlineOrigin returns the value of the KeyDeadlineOrigin tag for a server RPC, with the incoming
+// context ctx, that completed with the status: DeadlineOriginClient if the deadline set by the client,
+// such as with the grpc-timeout metadata, expired, or DeadlineOriginServer if the RPC exceeded a timeout
+// applied by Dapr, such as the server handler timeout or a resiliency policy. It returns an empty string,
+// which omits the tag, if the status is not DeadlineExceeded.
+func DeadlineOrigin(ctx context.Context, status string) string {
+	if status != codes.DeadlineExceeded.String() {
+		return ""
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return DeadlineOriginClient
+	}
+	return DeadlineOriginServer
+}
+
+// EnableDeadlineOriginTag adds the KeyDeadlineOrigin tag, set by DeadlineOrigin, to the server completed
+// RPCs view, to tell client deadlines apart from Dapr timeouts. It must be called before Init.
+func (g *grpcMetrics) EnableDeadlineOriginTag() {
+	if g == nil {
+		return
+	}
+	g.deadlineOriginTag = true
+}
+
+// deadlineOrigin returns the value of the KeyDeadlineOrigin tag for a server RPC, or an empty string,
+// which omits the tag, if the tag is disabled.
+func (g *grpcMetrics) deadlineOrigin(ctx context.Context, status string) string {
+	if !g.deadlineOriginTag {
+		return ""
+	}
+	return DeadlineOrigin(ctx, status)
+}
+
+// panicContextKey is the context key marking the RPCs whose handler panicked, set by recordPanic.
+type panicContextKey struct{}
+
+// panicked returns the value of the KeyPanic tag for a server RPC, "true" if its handler panicked, or an
+// empty string, which omits the tag, if the handler did not panic or the tag is disabled.
+func (g *grpcMetrics) panicked(ctx context.Context) string {
+	if !g.panicTag {
+		return ""
+	}
+	if p, _ := ctx.Value(panicContextKey{}).(bool); p {
+		return "true"
+	}
+	return ""
+}
+
+// sdk returns the value of the KeySDK tag for the user-agent of the incoming RPC in ctx,
+// or an empty string, which omits the tag, if the tag is disabled.
+func (g *grpcMetrics) sdk(ctx context.Context) string {
+	if !g.sdkTag {
+		return ""
+	}
+	var ua string
+	if vals := grpcMetadata.ValueFromIncomingContext(ctx, "user-agent"); len(vals) > 0 {
+		ua = vals[0]
+	}
+	return SDKFromUserAgent(ua)
+}
+
+// connectionSecurity returns the value of the KeyConnectionSecurity tag for the peer in ctx,
+// or an empty string, which omits the tag, if the tag is disabled or there is no peer.
+func (g *grpcMetrics) connectionSecurity(ctx context.Context) string {
+	if !g.connectionSecurityTag {
+		return ""
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if _, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+		return connectionSecurityMTLS
+	}
+	return connectionSecurityPlaintext
+}
+
+// SetTreatCanceledAsError sets whether RPCs canceled by the client are recorded in the error code metrics.
+// Canceled RPCs are always recorded with the "Canceled" status tag, so they can be told apart from errors.
+// Defaults to true.
+func (g *grpcMetrics) SetTreatCanceledAsError(enabled bool) {
+	if g == nil {
+		return
+	}
+	g.treatCanceledAsError = enabled
+}
+
+// recordError records err in the error code metrics, unless it is a cancellation
+// that should not be treated as an error.
+func (g *grpcMetrics) recordError(err error, code codes.Code) {
+	if code == codes.Canceled && !g.treatCanceledAsError {
+		return
+	}
+	RecordErrorCode(err)
+}
+
+// statusCode returns the gRPC status code of err. Context errors returned by
+// handlers are mapped to Canceled and DeadlineExceeded rather than Unknown.
+func statusCode(err error) codes.Code {
+	code := status.Code(err)
+	if code == codes.Unknown {
+		if s := status.FromContextError(err); s.Code() != codes.Unknown {
+			return s.Code()
+		}
+	}
+	return code
+}
+
+// CancellationReason returns why the RPC with the context ctx, which ended with err, was canceled:
+// CancellationReasonDeadline if a deadline was exceeded, CancellationReasonClient if the client canceled
+// the RPC, or CancellationReasonServer if the server canceled it while the client was still waiting.
+// It returns an empty string if the RPC was not canceled.
+func CancellationReason(ctx context.Context, err error) string {
+	code := statusCode(err)
+	if code != codes.Canceled && code != codes.DeadlineExceeded {
+		return ""
+	}
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return CancellationReasonDeadline
+	case errors.Is(ctx.Err(), context.Canceled):
+		return CancellationReasonClient
+	case code == codes.DeadlineExceeded:
+		return CancellationReasonDeadline
+	default:
+		return CancellationReasonServer
+	}
+}
+
+// SetSlowRequestThreshold sets the server latency above which a request is logged with its method,
+// elapsed time, status and trace ID. A zero threshold, the default, disables logging.
+func (g *grpcMetrics) SetSlowRequestThreshold(threshold time.Duration) {
+	if g == nil {
+		return
+	}
+	g.slowRequestThreshold = threshold
+}
+
+// logSlowRequest logs the request if it took longer than the slow request threshold.
+func (g *grpcMetrics) logSlowRequest(ctx context.Context, method string, code codes.Code, start time.Time) {
+	if g.slowRequestThreshold <= 0 {
+		return
+	}
+	elapsed := time.Since(start)
+	if elapsed < g.slowRequestThreshold {
+		return
+	}
+
+	fields := map[string]any{
+		"method":   method,
+		"duration": elapsed.Milliseconds(),
+		"status":   code.String(),
+	}
+	if sc := diagUtils.SpanFromContext(ctx).SpanContext(); sc.HasTraceID() {
+		fields["traceid"] = sc.TraceID().String()
+	}
+	log.WithFields(fields).Warn("Slow gRPC request")
+}
+
+// SetServerHandlerTimeout sets the maximum duration of unary handlers, independently of the client deadline.
+// The context of the handlers is canceled when it expires, and the RPCs whose handler ran for longer fail with
+// DeadlineExceeded. The interceptor still waits for the handler to return, so handlers that ignore the
+// cancellation of their context are not bounded. A zero timeout, the default, disables the enforcement.
+func (g *grpcMetrics) SetServerHandlerTimeout(timeout time.Duration) {
+	if g == nil {
+		return
+	}
+	g.serverHandlerTimeout = timeout
+}
+
+// SetRecordOnlyWhenSampled sets whether the latency, size and queue delay measures are recorded only for
+// RPCs that are part of a sampled trace, to reduce the cost of high-cardinality metrics. The completed RPCs
+// counters are always recorded.
+func (g *grpcMetrics) SetRecordOnlyWhenSampled(enabled bool) {
+	if g == nil {
+		return
+	}
+	g.recordOnlyWhenSampled = enabled
+}
+
+// recordDetailed returns true if the detailed measures should be recorded for the RPC in ctx.
+func (g *grpcMetrics) recordDetailed(ctx context.Context) bool {
+	return !g.recordOnlyWhenSampled || diagUtils.SpanFromContext(ctx).SpanContext().IsSampled()
 }
 
 func (g *grpcMetrics) IsEnabled() bool {
@@ -140,23 +1316,134 @@ func (g *grpcMetrics) ServerRequestSent(ctx context.Context, method, status stri
 		return
 	}
 
-	elapsed := float64(time.Since(start) / time.Millisecond)
+	elapsed := ElapsedSince(start)
+	connSecurity := g.connectionSecurity(ctx)
 	stats.RecordWithOptions(ctx,
 		stats.WithRecorder(g.meter),
-		stats.WithTags(diagUtils.WithTags(g.serverCompletedRpcs.Name(), appIDKey, g.appID, KeyServerMethod, method, KeyServerStatus, status)...),
+		g.withTags(g.serverCompletedRpcs.Name(), appIDKey, g.appID, KeyServerMethod, method, KeyServerService, g.completedRpcsService(method), KeyServerStatus, status, KeyConnectionSecurity, connSecurity, KeyRuntimeVersion, runtimeVersion, KeyTopic, g.topic(ctx), KeySDK, g.sdk(ctx), KeyErrorClass, g.errorClass(status), KeySampled, g.sampled(ctx), KeyDeadlineOrigin, g.deadlineOrigin(ctx, status), KeyPanic, g.panicked(ctx)),
 		stats.WithMeasurements(g.serverCompletedRpcs.M(1)))
+	if !g.recordDetailed(ctx) {
+		return
+	}
 	stats.RecordWithOptions(ctx,
 		stats.WithRecorder(g.meter),
-		stats.WithTags(diagUtils.WithTags(g.serverReceivedBytes.Name(), appIDKey, g.appID, KeyServerMethod, method)...),
+		g.withTags(g.serverReceivedBytes.Name(), appIDKey, g.appID, KeyServerMethod, method),
 		stats.WithMeasurements(g.serverReceivedBytes.M(reqContentSize)))
 	stats.RecordWithOptions(ctx,
 		stats.WithRecorder(g.meter),
-		stats.WithTags(diagUtils.WithTags(g.serverSentBytes.Name(), appIDKey, g.appID, KeyServerMethod, method)...),
+		g.withTags(g.serverSentBytes.Name(), appIDKey, g.appID, KeyServerMethod, method),
 		stats.WithMeasurements(g.serverSentBytes.M(resContentSize)))
 	stats.RecordWithOptions(ctx,
 		stats.WithRecorder(g.meter),
-		stats.WithTags(diagUtils.WithTags(g.serverLatency.Name(), appIDKey, g.appID, KeyServerMethod, method, KeyServerStatus, status)...),
-		stats.WithMeasurements(g.serverLatency.M(elapsed)))
+		g.withTags(g.serverLatency.Name(), appIDKey, g.appID, KeyServerMethod, method, KeyServerStatus, status, KeyConnectionSecurity, connSecurity, KeyRequestSizeClass, g.requestSizeClass(reqContentSize)),
+		g.latencyMeasurements(g.serverLatency, g.serverLatencySec, elapsed))
+	if p, ok := ctx.Value(downstreamDurationContextKey{}).(*atomic.Int64); ok {
+		self := max(elapsed-durationInMilliseconds(time.Duration(p.Load())), 0)
+		stats.RecordWithOptions(ctx,
+			stats.WithRecorder(g.meter),
+			g.withTags(g.serverSelfLatency.Name(), appIDKey, g.appID, KeyServerMethod, method),
+			stats.WithMeasurements(g.serverSelfLatency.M(self)))
+	}
+}
+
+// ServerRequestAdmitted records the time a request waited between arriving at the server and its handler being invoked.
+// It is a no-op if the arrival time was not recorded in the context.
+func (g *grpcMetrics) ServerRequestAdmitted(ctx context.Context, method string, start time.Time) {
+	if !g.IsEnabled() || !g.recordDetailed(ctx) {
+		return
+	}
+
+	arrival, ok := metadata.ArrivalTimeFromContext(ctx)
+	if !ok {
+		return
+	}
+
+	delay := durationInMilliseconds(start.Sub(arrival))
+	stats.RecordWithOptions(ctx,
+		stats.WithRecorder(g.meter),
+		g.withTags(g.serverQueueDelay.Name(), appIDKey, g.appID, KeyServerMethod, method),
+		stats.WithMeasurements(g.serverQueueDelay.M(delay)))
+}
+
+// ServerStreamMessage records the size of a single message received or sent on a streaming RPC.
+func (g *grpcMetrics) ServerStreamMessage(ctx context.Context, method string, size int64) {
+	if !g.IsEnabled() || !g.recordDetailed(ctx) {
+		return
+	}
+
+	stats.RecordWithOptions(ctx,
+		stats.WithRecorder(g.meter),
+		g.withTags(g.serverMessageSize.Name(), appIDKey, g.appID, KeyServerMethod, method),
+		stats.WithMeasurements(g.serverMessageSize.M(size)))
+}
+
+// StreamHalfCloseCompleted records the time from the client half-closing a stream, at halfClosedAt,
+// to the server completing it, which shows servers that are slow to drain streams.
+func (g *grpcMetrics) StreamHalfCloseCompleted(ctx context.Context, method string, halfClosedAt time.Time) {
+	if !g.IsEnabled() || !g.recordDetailed(ctx) {
+		return
+	}
+
+	stats.RecordWithOptions(ctx,
+		stats.WithRecorder(g.meter),
+		g.withTags(g.streamHalfCloseToCompletion.Name(), appIDKey, g.appID, KeyServerMethod, method),
+		stats.WithMeasurements(g.streamHalfCloseToCompletion.M(ElapsedSince(halfClosedAt))))
+}
+
+// ServerHandlerTimedOut records a handler exceeding the server handler timeout.
+func (g *grpcMetrics) ServerHandlerTimedOut(ctx context.Context, method string) {
+	if !g.IsEnabled() {
+		return
+	}
+
+	stats.RecordWithOptions(ctx,
+		stats.WithRecorder(g.meter),
+		g.withTags(g.serverTimeouts.Name(), appIDKey, g.appID, KeyServerMethod, method),
+		stats.WithMeasurements(g.serverTimeouts.M(1)))
+}
+
+// SerializationCompleted records the time spent on a conversion between Protobuf and JSON,
+// and adds it as an attribute to the span in ctx. Operation is "marshal" or "unmarshal".
+func (g *grpcMetrics) SerializationCompleted(ctx context.Context, operation string, start time.Time) {
+	if !g.SerializationMetricsEnabled() {
+		return
+	}
+
+	elapsed := ElapsedSince(start)
+	stats.RecordWithOptions(ctx,
+		stats.WithRecorder(g.meter),
+		g.withTags(g.serializationLatency.Name(), appIDKey, g.appID, operationKey, operation),
+		stats.WithMeasurements(g.serializationLatency.M(elapsed)))
+
+	if span := diagUtils.SpanFromContext(ctx); span.IsRecording() {
+		span.SetAttributes(attribute.Float64(diagConsts.DaprSerializationMsAttributeKey, elapsed))
+	}
+}
+
+// ServerRequestCanceled records a canceled RPC, tagged with its cancellation reason.
+// It is a no-op if reason is empty.
+func (g *grpcMetrics) ServerRequestCanceled(ctx context.Context, method, reason string) {
+	if !g.IsEnabled() || reason == "" {
+		return
+	}
+
+	stats.RecordWithOptions(ctx,
+		stats.WithRecorder(g.meter),
+		g.withTags(g.serverCanceledRpcs.Name(), appIDKey, g.appID, KeyServerMethod, method, KeyCancellationReason, reason),
+		stats.WithMeasurements(g.serverCanceledRpcs.M(1)))
+}
+
+// MetadataConversionCompleted records the time spent converting internal metadata.
+// Operation is "to_grpc" or "to_http".
+func (g *grpcMetrics) MetadataConversionCompleted(ctx context.Context, operation string, start time.Time) {
+	if !g.MetadataConversionMetricsEnabled() {
+		return
+	}
+
+	stats.RecordWithOptions(ctx,
+		stats.WithRecorder(g.meter),
+		g.withTags(g.metadataConversionLatency.Name(), appIDKey, g.appID, operationKey, operation),
+		stats.WithMeasurements(g.metadataConversionLatency.M(ElapsedSince(start))))
 }
 
 func (g *grpcMetrics) StreamServerRequestSent(ctx context.Context, method, status string, start time.Time) {
@@ -164,15 +1451,19 @@ func (g *grpcMetrics) StreamServerRequestSent(ctx context.Context, method, statu
 		return
 	}
 
-	elapsed := float64(time.Since(start) / time.Millisecond)
+	elapsed := ElapsedSince(start)
+	connSecurity := g.connectionSecurity(ctx)
 	stats.RecordWithOptions(ctx,
 		stats.WithRecorder(g.meter),
-		stats.WithTags(diagUtils.WithTags(g.serverCompletedRpcs.Name(), appIDKey, g.appID, KeyServerMethod, method, KeyServerStatus, status)...),
+		g.withTags(g.serverCompletedRpcs.Name(), appIDKey, g.appID, KeyServerMethod, method, KeyServerService, g.completedRpcsService(method), KeyServerStatus, status, KeyConnectionSecurity, connSecurity, KeyRuntimeVersion, runtimeVersion, KeySDK, g.sdk(ctx), KeyErrorClass, g.errorClass(status), KeySampled, g.sampled(ctx), KeyDeadlineOrigin, g.deadlineOrigin(ctx, status), KeyPanic, g.panicked(ctx)),
 		stats.WithMeasurements(g.serverCompletedRpcs.M(1)))
+	if !g.recordDetailed(ctx) {
+		return
+	}
 	stats.RecordWithOptions(ctx,
 		stats.WithRecorder(g.meter),
-		stats.WithTags(diagUtils.WithTags(g.serverLatency.Name(), appIDKey, g.appID, KeyServerMethod, method, KeyServerStatus, status)...),
-		stats.WithMeasurements(g.serverLatency.M(elapsed)))
+		g.withTags(g.serverLatency.Name(), appIDKey, g.appID, KeyServerMethod, method, KeyServerStatus, status, KeyConnectionSecurity, connSecurity),
+		g.latencyMeasurements(g.serverLatency, g.serverLatencySec, elapsed))
 }
 
 func (g *grpcMetrics) StreamClientRequestSent(ctx context.Context, method, status string, start time.Time) {
@@ -180,82 +1471,217 @@ func (g *grpcMetrics) StreamClientRequestSent(ctx context.Context, method, statu
 		return
 	}
 
-	elapsed := float64(time.Since(start) / time.Millisecond)
+	elapsed := ElapsedSince(start)
 	stats.RecordWithOptions(ctx,
 		stats.WithRecorder(g.meter),
-		stats.WithTags(diagUtils.WithTags(g.clientCompletedRpcs.Name(), appIDKey, g.appID, KeyClientMethod, method, KeyClientStatus, status)...),
+		g.withTags(g.clientCompletedRpcs.Name(), appIDKey, g.appID, KeyClientMethod, method, KeyClientStatus, status, KeyRuntimeVersion, runtimeVersion, KeySampled, g.sampled(ctx)),
 		stats.WithMeasurements(g.clientCompletedRpcs.M(1)))
+	if !g.recordDetailed(ctx) {
+		return
+	}
 	stats.RecordWithOptions(ctx,
 		stats.WithRecorder(g.meter),
-		stats.WithTags(diagUtils.WithTags(g.clientRoundtripLatency.Name(), appIDKey, g.appID, KeyClientMethod, method, KeyClientStatus, status)...),
-		stats.WithMeasurements(g.clientRoundtripLatency.M(elapsed)))
+		g.withTags(g.clientRoundtripLatency.Name(), appIDKey, g.appID, KeyClientMethod, method, KeyClientStatus, status),
+		g.latencyMeasurements(g.clientRoundtripLatency, g.clientRoundtripLatencySec, elapsed))
 }
 
 func (g *grpcMetrics) ClientRequestReceived(ctx context.Context, method, status string, reqContentSize, resContentSize int64, start time.Time) {
+	g.clientRequestReceived(ctx, method, status, "", reqContentSize, resContentSize, start)
+}
+
+// clientRequestReceived records a completed unary call, with the value of the KeyClientWaitForReady tag.
+func (g *grpcMetrics) clientRequestReceived(ctx context.Context, method, status, waitForReady string, reqContentSize, resContentSize int64, start time.Time) {
 	if !g.IsEnabled() {
 		return
 	}
 
-	elapsed := float64(time.Since(start) / time.Millisecond)
+	elapsed := ElapsedSince(start)
 	stats.RecordWithOptions(ctx,
 		stats.WithRecorder(g.meter),
-		stats.WithTags(diagUtils.WithTags(g.clientCompletedRpcs.Name(), appIDKey, g.appID, KeyClientMethod, method, KeyClientStatus, status)...),
+		g.withTags(g.clientCompletedRpcs.Name(), appIDKey, g.appID, KeyClientMethod, method, KeyClientStatus, status, KeyRuntimeVersion, runtimeVersion, KeyClientWaitForReady, waitForReady, KeySampled, g.sampled(ctx)),
 		stats.WithMeasurements(g.clientCompletedRpcs.M(1)))
+	if !g.recordDetailed(ctx) {
+		return
+	}
 	stats.RecordWithOptions(ctx,
 		stats.WithRecorder(g.meter),
-		stats.WithTags(diagUtils.WithTags(g.clientRoundtripLatency.Name(), appIDKey, g.appID, KeyClientMethod, method, KeyClientStatus, status)...),
-		stats.WithMeasurements(g.clientRoundtripLatency.M(elapsed)))
+		g.withTags(g.clientRoundtripLatency.Name(), appIDKey, g.appID, KeyClientMethod, method, KeyClientStatus, status, KeyClientWaitForReady, waitForReady),
+		g.latencyMeasurements(g.clientRoundtripLatency, g.clientRoundtripLatencySec, elapsed))
 	stats.RecordWithOptions(ctx,
 		stats.WithRecorder(g.meter),
-		stats.WithTags(diagUtils.WithTags(g.clientSentBytes.Name(), appIDKey, g.appID, KeyClientMethod, method)...),
+		g.withTags(g.clientSentBytes.Name(), appIDKey, g.appID, KeyClientMethod, method),
 		stats.WithMeasurements(g.clientSentBytes.M(reqContentSize)))
 	stats.RecordWithOptions(ctx,
 		stats.WithRecorder(g.meter),
-		stats.WithTags(diagUtils.WithTags(g.clientReceivedBytes.Name(), appIDKey, g.appID, KeyClientMethod, method)...),
+		g.withTags(g.clientReceivedBytes.Name(), appIDKey, g.appID, KeyClientMethod, method),
 		stats.WithMeasurements(g.clientReceivedBytes.M(resContentSize)))
 }
 
+// PayloadCompressed records the ratio of the uncompressed to the compressed size of a payload, tagged
+// by the bucket of its content type returned by ContentTypeBucket, to compare how well content types
+// compress. Nothing is recorded unless both sizes are known and positive.
+func (g *grpcMetrics) PayloadCompressed(ctx context.Context, contentType string, uncompressedSize, compressedSize int64) {
+	if !g.IsEnabled() || uncompressedSize <= 0 || compressedSize <= 0 {
+		return
+	}
+
+	stats.RecordWithOptions(ctx,
+		stats.WithRecorder(g.meter),
+		g.withTags(g.payloadCompressionRatio.Name(), appIDKey, g.appID, KeyContentType, ContentTypeBucket(contentType)),
+		stats.WithMeasurements(g.payloadCompressionRatio.M(float64(uncompressedSize)/float64(compressedSize))))
+}
+
+// ErrorDetailRecorded records a detail attached to a gRPC status error, such as a google.rpc.ErrorInfo,
+// tagged by its type URL, to know which detail types are used the most.
+func (g *grpcMetrics) ErrorDetailRecorded(ctx context.Context, typeURL string) {
+	if !g.IsEnabled() {
+		return
+	}
+
+	stats.RecordWithOptions(ctx,
+		stats.WithRecorder(g.meter),
+		g.withTags(g.errorDetailsByType.Name(), appIDKey, g.appID, KeyErrorDetailType, typeURL),
+		stats.WithMeasurements(g.errorDetailsByType.M(1)))
+}
+
+// HeadersTrimmed records a set of HTTP headers from which non-essential headers were dropped
+// because it exceeded the maximum header size.
+func (g *grpcMetrics) HeadersTrimmed(ctx context.Context) {
+	if !g.IsEnabled() {
+		return
+	}
+
+	stats.RecordWithOptions(ctx,
+		stats.WithRecorder(g.meter),
+		g.withTags(g.headersTrimmed.Name(), appIDKey, g.appID),
+		stats.WithMeasurements(g.headersTrimmed.M(1)))
+}
+
 func (g *grpcMetrics) AppHealthProbeCompleted(ctx context.Context, status string, start time.Time) {
 	if !g.IsEnabled() {
 		return
 	}
 
-	elapsed := float64(time.Since(start) / time.Millisecond)
+	elapsed := ElapsedSince(start)
 	stats.RecordWithOptions(ctx,
 		stats.WithRecorder(g.meter),
-		stats.WithTags(diagUtils.WithTags(g.healthProbeCompletedCount.Name(), appIDKey, g.appID, KeyClientStatus, status)...),
+		g.withTags(g.healthProbeCompletedCount.Name(), appIDKey, g.appID, KeyClientStatus, status),
 		stats.WithMeasurements(g.healthProbeCompletedCount.M(1)))
 	stats.RecordWithOptions(ctx,
 		stats.WithRecorder(g.meter),
-		stats.WithTags(diagUtils.WithTags(g.healthProbeRoundtripLatency.Name(), appIDKey, g.appID, KeyClientStatus, status)...),
-		stats.WithMeasurements(g.healthProbeRoundtripLatency.M(elapsed)))
+		g.withTags(g.healthProbeRoundtripLatency.Name(), appIDKey, g.appID, KeyClientStatus, status),
+		g.latencyMeasurements(g.healthProbeRoundtripLatency, g.healthProbeRoundtripLatencySec, elapsed))
+}
+
+// getMessageSize returns the size of a streamed message, which is either a proto message
+// or a raw frame forwarded by the gRPC proxy.
+func (g *grpcMetrics) getMessageSize(msg any) int {
+	if s, ok := msg.(interface{ Size() int }); ok {
+		return s.Size()
+	}
+	return g.getPayloadSize(msg)
 }
 
 func (g *grpcMetrics) getPayloadSize(payload any) int {
-	return proto.Size(payload.(proto.Message))
+	msg, ok := payload.(proto.Message)
+	if !ok {
+		return 0
+	}
+	return proto.Size(msg)
 }
 
 // UnaryServerInterceptor is a gRPC server-side interceptor for Unary RPCs.
 func (g *grpcMetrics) UnaryServerInterceptor() func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if g == nil {
+			return handler(ctx, req)
+		}
+
+		method, ok := g.recordedMethod(SanitizeMetricLabelValue(info.FullMethod))
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		ctx = g.withTopic(ctx)
+		ctx = g.withDownstreamDuration(ctx)
 		start := time.Now()
-		resp, err := handler(ctx, req)
+		defer g.recordPanic(ctx, func(ctx context.Context, status string) {
+			g.ServerRequestSent(ctx, method, status, int64(g.getPayloadSize(req)), 0, start)
+		})
+		g.ServerRequestAdmitted(ctx, method, start)
+		resp, err := g.invokeUnaryHandler(ctx, req, method, handler)
 		size := 0
 		if err == nil {
 			size = g.getPayloadSize(resp)
 		}
-		g.ServerRequestSent(ctx, info.FullMethod, status.Code(err).String(), int64(g.getPayloadSize(req)), int64(size), start)
+		code := statusCode(err)
+		g.ServerRequestSent(ctx, method, code.String(), int64(g.getPayloadSize(req)), int64(size), start)
+		g.logSlowRequest(ctx, method, code, start)
 
 		if err != nil {
-			RecordErrorCode(err)
+			g.ServerRequestCanceled(ctx, method, CancellationReason(ctx, err))
+			g.recordError(err, code)
 		}
 		return resp, err
 	}
 }
 
+// EnablePanicTag adds the KeyPanic tag to the server completed RPCs view, set to "true" for the RPCs
+// whose handler panicked, to tell crashes apart from other Internal errors. It must be called before Init.
+func (g *grpcMetrics) EnablePanicTag() {
+	if g == nil {
+		return
+	}
+	g.panicTag = true
+}
+
+// recordPanic records a server RPC whose handler panicked as completed with the Internal status and,
+// if enabled, the KeyPanic tag, with record, then panics again so that recovery middleware still handles
+// the panic. record is the function recording the completed RPCs of the interceptor, so the RPCs that
+// panicked have the same tags and sampling as the others. It must be deferred by the server interceptors,
+// as it recovers the panic; it is a no-op if the handler did not panic.
+func (g *grpcMetrics) recordPanic(ctx context.Context, record func(ctx context.Context, status string)) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	record(context.WithValue(ctx, panicContextKey{}, true), codes.Internal.String())
+	panic(r)
+}
+
+// invokeUnaryHandler invokes the handler, enforcing the server handler timeout if set. The result of the
+// handler is replaced with DeadlineExceeded only if the handler itself ran for longer than the timeout, so
+// that the response of a handler that returned just as the timeout expired is not discarded.
+func (g *grpcMetrics) invokeUnaryHandler(ctx context.Context, req any, method string, handler grpc.UnaryHandler) (any, error) {
+	if g.serverHandlerTimeout <= 0 {
+		return handler(ctx, req)
+	}
+
+	handlerCtx, cancel := context.WithTimeoutCause(ctx, g.serverHandlerTimeout, errServerHandlerTimeout)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := handler(handlerCtx, req)
+	if ctx.Err() == nil && time.Since(start) >= g.serverHandlerTimeout {
+		g.ServerHandlerTimedOut(ctx, method)
+		return nil, status.Errorf(codes.DeadlineExceeded, "handler exceeded the server timeout of %v", g.serverHandlerTimeout)
+	}
+	return resp, err
+}
+
 // UnaryClientInterceptor is a gRPC client-side interceptor for Unary RPCs.
 func (g *grpcMetrics) UnaryClientInterceptor() func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if g == nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		recordedMethod, ok := g.recordedMethod(method)
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
 		start := time.Now()
 		err := invoker(ctx, method, req, reply, cc, opts...)
 
@@ -264,14 +1690,15 @@ func (g *grpcMetrics) UnaryClientInterceptor() func(ctx context.Context, method
 			resSize = g.getPayloadSize(reply)
 		}
 
-		if method == appHealthCheckMethod {
-			g.AppHealthProbeCompleted(ctx, status.Code(err).String(), start)
+		code := statusCode(err)
+		if isHealthCheckMethod(method) {
+			g.AppHealthProbeCompleted(ctx, code.String(), start)
 		} else {
-			g.ClientRequestReceived(ctx, method, status.Code(err).String(), int64(g.getPayloadSize(req)), int64(resSize), start)
+			g.clientRequestReceived(ctx, recordedMethod, code.String(), g.waitForReady(opts), int64(g.getPayloadSize(req)), int64(resSize), start)
 		}
 
 		if err != nil {
-			RecordErrorCode(err)
+			g.recordError(err, code)
 		}
 		return err
 	}
@@ -280,6 +1707,10 @@ func (g *grpcMetrics) UnaryClientInterceptor() func(ctx context.Context, method
 // StreamingServerInterceptor is a stream interceptor for gRPC proxying calls that arrive from the application to Dapr
 func (g *grpcMetrics) StreamingServerInterceptor() grpc.StreamServerInterceptor {
 	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if g == nil {
+			return handler(srv, ss)
+		}
+
 		ctx := ss.Context()
 		md, _ := metadata.FromIncomingContext(ctx)
 		vals, ok := md[diagConsts.GRPCProxyAppIDKey]
@@ -287,12 +1718,32 @@ func (g *grpcMetrics) StreamingServerInterceptor() grpc.StreamServerInterceptor
 			return handler(srv, ss)
 		}
 
+		method, ok := g.recordedMethod(SanitizeMetricLabelValue(info.FullMethod))
+		if !ok {
+			return handler(srv, ss)
+		}
+
 		now := time.Now()
-		err := handler(srv, ss)
-		g.StreamServerRequestSent(ctx, info.FullMethod, status.Code(err).String(), now)
+		defer g.recordPanic(ctx, func(ctx context.Context, status string) {
+			g.StreamServerRequestSent(ctx, method, status, now)
+		})
+		g.ServerRequestAdmitted(ctx, method, now)
+		stream := &monitoredServerStream{
+			ServerStream: ss,
+			metrics:      g,
+			method:       method,
+		}
+		err := handler(srv, stream)
+		if halfClosedAt := stream.halfClosedAt.Load(); halfClosedAt != 0 {
+			g.StreamHalfCloseCompleted(ctx, method, time.Unix(0, halfClosedAt))
+		}
+		code := statusCode(err)
+		g.StreamServerRequestSent(ctx, method, code.String(), now)
+		g.logSlowRequest(ctx, method, code, now)
 
 		if err != nil {
-			RecordErrorCode(err)
+			g.ServerRequestCanceled(ctx, method, CancellationReason(ctx, err))
+			g.recordError(err, code)
 		}
 		return err
 	}
@@ -301,6 +1752,10 @@ func (g *grpcMetrics) StreamingServerInterceptor() grpc.StreamServerInterceptor
 // StreamingClientInterceptor is a stream interceptor for gRPC proxying calls that arrive from a remote Dapr sidecar
 func (g *grpcMetrics) StreamingClientInterceptor() grpc.StreamServerInterceptor {
 	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if g == nil {
+			return handler(srv, ss)
+		}
+
 		ctx := ss.Context()
 		md, _ := metadata.FromIncomingContext(ctx)
 		vals, ok := md[diagConsts.GRPCProxyAppIDKey]
@@ -308,13 +1763,51 @@ func (g *grpcMetrics) StreamingClientInterceptor() grpc.StreamServerInterceptor
 			return handler(srv, ss)
 		}
 
+		method, ok := g.recordedMethod(SanitizeMetricLabelValue(info.FullMethod))
+		if !ok {
+			return handler(srv, ss)
+		}
+
 		now := time.Now()
 		err := handler(srv, ss)
-		g.StreamClientRequestSent(ctx, info.FullMethod, status.Code(err).String(), now)
+		code := statusCode(err)
+		g.StreamClientRequestSent(ctx, method, code.String(), now)
 
 		if err != nil {
-			RecordErrorCode(err)
+			g.recordError(err, code)
 		}
 		return err
 	}
 }
+
+// monitoredServerStream wraps a grpc.ServerStream to record the size of each message
+// and the time the client half-closed the stream.
+type monitoredServerStream struct {
+	grpc.ServerStream
+
+	metrics *grpcMetrics
+	method  string
+	// halfClosedAt is the time, in unix nanoseconds, RecvMsg first returned io.EOF, or 0.
+	// It is atomic as RecvMsg may be called from a goroutine that outlives the handler, such as
+	// the one forwarding the client messages in the proxy.
+	halfClosedAt atomic.Int64
+}
+
+func (s *monitoredServerStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	switch {
+	case err == nil:
+		s.metrics.ServerStreamMessage(s.Context(), s.method, int64(s.metrics.getMessageSize(m)))
+	case errors.Is(err, io.EOF):
+		s.halfClosedAt.CompareAndSwap(0, time.Now().UnixNano())
+	}
+	return err
+}
+
+func (s *monitoredServerStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.metrics.ServerStreamMessage(s.Context(), s.method, int64(s.metrics.getMessageSize(m)))
+	}
+	return err
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/stats"
+	"google.golang.org/grpc"
+)
+
+// componentContextKey is the context key of the componentInfo set by WithComponent.
+type componentContextKey struct{}
+
+// componentInfo identifies the pluggable component called with a context.
+type componentInfo struct {
+	componentType string
+	name          string
+}
+
+// WithComponent returns a copy of ctx that identifies the pluggable component, such as
+// "state.redis-pluggable" and "mystore", that RPCs made with it are sent to. The calls
+// are tagged with the component by ComponentUnaryClientInterceptor.
+func WithComponent(ctx context.Context, componentType, name string) context.Context {
+	return context.WithValue(ctx, componentContextKey{}, componentInfo{componentType: componentType, name: name})
+}
+
+// componentFromContext returns the component set on ctx by WithComponent.
+func componentFromContext(ctx context.Context) (componentInfo, bool) {
+	info, ok := ctx.Value(componentContextKey{}).(componentInfo)
+	return info, ok
+}
+
+// ComponentUnaryClientInterceptor is a gRPC client-side interceptor for Unary RPCs to pluggable
+// components, which run out of process. It records the latency and the status of the calls made with
+// a context set by WithComponent, tagged by the type and name of the component; other calls are not
+// recorded. It must be installed on the connections to the components with grpc.WithUnaryInterceptor.
+func (g *grpcMetrics) ComponentUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if g == nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		component, ok := componentFromContext(ctx)
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		code := statusCode(err)
+		g.componentRequestCompleted(ctx, component, method, code.String(), start)
+		if err != nil {
+			g.recordError(err, code)
+		}
+		return err
+	}
+}
+
+func (g *grpcMetrics) componentRequestCompleted(ctx context.Context, component componentInfo, method, status string, start time.Time) {
+	if !g.IsEnabled() {
+		return
+	}
+
+	elapsed := ElapsedSince(start)
+	stats.RecordWithOptions(ctx,
+		stats.WithRecorder(g.meter),
+		g.withTags(g.componentCompletedRpcs.Name(), appIDKey, g.appID, KeyComponentType, component.componentType, KeyComponentName, component.name, KeyClientMethod, method, KeyClientStatus, status),
+		stats.WithMeasurements(g.componentCompletedRpcs.M(1)))
+	stats.RecordWithOptions(ctx,
+		stats.WithRecorder(g.meter),
+		g.withTags(g.componentRoundtripLatency.Name(), appIDKey, g.appID, KeyComponentType, component.componentType, KeyComponentName, component.name, KeyClientMethod, method, KeyClientStatus, status),
+		stats.WithMeasurements(g.componentRoundtripLatency.M(elapsed)))
+}
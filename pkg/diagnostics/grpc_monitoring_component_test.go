@@ -0,0 +1,88 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dapr/dapr/pkg/config"
+)
+
+func TestComponentUnaryClientInterceptor(t *testing.T) {
+	const method = "/dapr.proto.components.v1.StateStore/Get"
+
+	newMetrics := func(t *testing.T) (*grpcMetrics, view.Meter) {
+		m := newGRPCMetrics()
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+		return m, meter
+	}
+
+	t.Run("records the call tagged with the component", func(t *testing.T) {
+		m, meter := newMetrics(t)
+		ctx := WithComponent(t.Context(), "state.redis-pluggable", "mystore")
+
+		require.NoError(t, m.ComponentUnaryClientInterceptor()(ctx, method, nil, nil, nil, func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return nil
+		}))
+		err := m.ComponentUnaryClientInterceptor()(ctx, method, nil, nil, nil, func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return status.Error(codes.Unavailable, "component is down")
+		})
+		require.Equal(t, codes.Unavailable, status.Code(err))
+
+		rows, err := meter.RetrieveData("grpc.io/component/completed_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		RequireTagExist(t, rows, NewTag(KeyComponentType.Name(), "state.redis-pluggable"))
+		RequireTagExist(t, rows, NewTag(KeyComponentName.Name(), "mystore"))
+		RequireTagExist(t, rows, NewTag(KeyClientMethod.Name(), method))
+		assert.Equal(t, int64(1), GetCountValueForObservationWithTagSet(rows, map[tag.Tag]bool{NewTag(KeyClientStatus.Name(), codes.OK.String()): true}))
+		assert.Equal(t, int64(1), GetCountValueForObservationWithTagSet(rows, map[tag.Tag]bool{NewTag(KeyClientStatus.Name(), codes.Unavailable.String()): true}))
+
+		rows, err = meter.RetrieveData("grpc.io/component/roundtrip_latency")
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		RequireTagExist(t, rows, NewTag(KeyComponentName.Name(), "mystore"))
+
+		// The calls are not recorded as app client calls.
+		rows, err = meter.RetrieveData("grpc.io/client/completed_rpcs")
+		require.NoError(t, err)
+		assert.Empty(t, rows)
+	})
+
+	t.Run("calls without a component are not recorded", func(t *testing.T) {
+		m, meter := newMetrics(t)
+
+		require.NoError(t, m.ComponentUnaryClientInterceptor()(t.Context(), method, nil, nil, nil, func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return nil
+		}))
+
+		rows, err := meter.RetrieveData("grpc.io/component/completed_rpcs")
+		require.NoError(t, err)
+		assert.Empty(t, rows)
+	})
+}
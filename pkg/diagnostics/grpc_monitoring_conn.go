@@ -0,0 +1,126 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"go.opencensus.io/stats"
+	grpcStats "google.golang.org/grpc/stats"
+)
+
+// connContextKey is the context key of the connection set by TagConn.
+type connContextKey struct{}
+
+// ClientStatsHandler returns a gRPC stats.Handler that records the client connections that are created,
+// and the RPCs that reuse a connection which already carried an earlier RPC, to measure the efficiency
+// of connection pooling. It must be installed on client connections with grpc.WithStatsHandler:
+//
+//	grpc.NewClient(target, grpc.WithStatsHandler(diag.DefaultGRPCMonitoring.ClientStatsHandler()))
+//
+// Each returned handler only tracks the connections it is installed on.
+func (g *grpcMetrics) ClientStatsHandler() grpcStats.Handler {
+	return &connStatsHandler{
+		metrics: g,
+		conns:   make(map[connAddrs]bool),
+	}
+}
+
+// connStatsHandler is the stats.Handler returned by ClientStatsHandler.
+type connStatsHandler struct {
+	metrics *grpcMetrics
+
+	lock sync.Mutex
+	// conns are the open connections and whether they carried an RPC.
+	conns map[connAddrs]bool
+}
+
+// connAddrs identifies a connection by the addresses in its ConnTagInfo. The gRPC transport passes
+// the same net.Addr values, which the net package allocates for each connection, in the ConnTagInfo
+// and in the OutHeader of every RPC sent on the connection. They are compared by identity rather than
+// by their string form, which is the same for all the connections to a Unix domain socket.
+type connAddrs struct {
+	local  net.Addr
+	remote net.Addr
+}
+
+func (h *connStatsHandler) TagConn(ctx context.Context, info *grpcStats.ConnTagInfo) context.Context {
+	return context.WithValue(ctx, connContextKey{}, info)
+}
+
+func (h *connStatsHandler) HandleConn(ctx context.Context, s grpcStats.ConnStats) {
+	info, ok := ctx.Value(connContextKey{}).(*grpcStats.ConnTagInfo)
+	if !ok {
+		return
+	}
+	key := connAddrs{local: info.LocalAddr, remote: info.RemoteAddr}
+	switch s := s.(type) {
+	case *grpcStats.ConnBegin:
+		if !s.Client {
+			return
+		}
+		h.lock.Lock()
+		h.conns[key] = false
+		h.lock.Unlock()
+		h.metrics.clientConnectionCreated(ctx)
+	case *grpcStats.ConnEnd:
+		h.lock.Lock()
+		delete(h.conns, key)
+		h.lock.Unlock()
+	}
+}
+
+func (h *connStatsHandler) TagRPC(ctx context.Context, _ *grpcStats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (h *connStatsHandler) HandleRPC(ctx context.Context, s grpcStats.RPCStats) {
+	// The outgoing header of a client RPC carries the addresses of the connection it is sent on.
+	header, ok := s.(*grpcStats.OutHeader)
+	if !ok || !header.Client {
+		return
+	}
+	key := connAddrs{local: header.LocalAddr, remote: header.RemoteAddr}
+	h.lock.Lock()
+	reused, open := h.conns[key]
+	if open {
+		h.conns[key] = true
+	}
+	h.lock.Unlock()
+	if reused {
+		h.metrics.clientConnectionReused(ctx)
+	}
+}
+
+func (g *grpcMetrics) clientConnectionCreated(ctx context.Context) {
+	if !g.IsEnabled() {
+		return
+	}
+	stats.RecordWithOptions(ctx,
+		stats.WithRecorder(g.meter),
+		g.withTags(g.clientConnectionsCreated.Name(), appIDKey, g.appID),
+		stats.WithMeasurements(g.clientConnectionsCreated.M(1)))
+}
+
+func (g *grpcMetrics) clientConnectionReused(ctx context.Context) {
+	if !g.IsEnabled() {
+		return
+	}
+	stats.RecordWithOptions(ctx,
+		stats.WithRecorder(g.meter),
+		g.withTags(g.clientConnectionsReused.Name(), appIDKey, g.appID),
+		stats.WithMeasurements(g.clientConnectionsReused.M(1)))
+}
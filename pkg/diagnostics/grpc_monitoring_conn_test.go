@@ -0,0 +1,101 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	grpcStats "google.golang.org/grpc/stats"
+
+	"github.com/dapr/dapr/pkg/config"
+)
+
+func TestClientStatsHandler(t *testing.T) {
+	m := newGRPCMetrics()
+	meter := view.NewMeter()
+	meter.Start()
+	t.Cleanup(func() {
+		meter.Stop()
+	})
+	require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+
+	h := m.ClientStatsHandler()
+	remote := &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 50001}
+
+	// openConn drives the events of a new client connection from the given local port.
+	openConn := func(localPort int) (context.Context, *net.TCPAddr) {
+		local := &net.TCPAddr{IP: net.IPv4(10, 0, 0, 2), Port: localPort}
+		ctx := h.TagConn(t.Context(), &grpcStats.ConnTagInfo{LocalAddr: local, RemoteAddr: remote})
+		h.HandleConn(ctx, &grpcStats.ConnBegin{Client: true})
+		return ctx, local
+	}
+	sendRPC := func(local *net.TCPAddr) {
+		ctx := h.TagRPC(t.Context(), &grpcStats.RPCTagInfo{FullMethodName: "/svc/Method"})
+		h.HandleRPC(ctx, &grpcStats.Begin{Client: true})
+		h.HandleRPC(ctx, &grpcStats.OutHeader{Client: true, FullMethod: "/svc/Method", LocalAddr: local, RemoteAddr: remote})
+		h.HandleRPC(ctx, &grpcStats.End{Client: true})
+	}
+	count := func(name string) int64 {
+		rows, err := meter.RetrieveData(name)
+		require.NoError(t, err)
+		if len(rows) == 0 {
+			return 0
+		}
+		return rows[0].Data.(*view.CountData).Value
+	}
+
+	connCtx, local := openConn(40001)
+	sendRPC(local)
+	sendRPC(local)
+	sendRPC(local)
+	assert.Equal(t, int64(1), count("grpc.io/client/connections_created"))
+	assert.Equal(t, int64(2), count("grpc.io/client/connections_reused"))
+
+	// A new connection, after the first is closed, is created and its first RPC is not a reuse.
+	h.HandleConn(connCtx, &grpcStats.ConnEnd{Client: true})
+	_, local = openConn(40002)
+	sendRPC(local)
+	assert.Equal(t, int64(2), count("grpc.io/client/connections_created"))
+	assert.Equal(t, int64(2), count("grpc.io/client/connections_reused"))
+
+	// Connections to a Unix domain socket have the same addresses, but are still told apart.
+	socket := &net.UnixAddr{Name: "/tmp/dapr-component.sock", Net: "unix"}
+	openUnixConn := func() *grpcStats.ConnTagInfo {
+		info := &grpcStats.ConnTagInfo{LocalAddr: &net.UnixAddr{Net: "unix"}, RemoteAddr: socket}
+		h.HandleConn(h.TagConn(t.Context(), info), &grpcStats.ConnBegin{Client: true})
+		return info
+	}
+	sendUnixRPC := func(info *grpcStats.ConnTagInfo) {
+		ctx := h.TagRPC(t.Context(), &grpcStats.RPCTagInfo{FullMethodName: "/svc/Method"})
+		h.HandleRPC(ctx, &grpcStats.OutHeader{Client: true, FullMethod: "/svc/Method", LocalAddr: info.LocalAddr, RemoteAddr: info.RemoteAddr})
+	}
+	first, second := openUnixConn(), openUnixConn()
+	require.Equal(t, first.LocalAddr.String(), second.LocalAddr.String())
+	sendUnixRPC(first)
+	sendUnixRPC(second)
+	assert.Equal(t, int64(4), count("grpc.io/client/connections_created"))
+	assert.Equal(t, int64(2), count("grpc.io/client/connections_reused"))
+	sendUnixRPC(second)
+	assert.Equal(t, int64(3), count("grpc.io/client/connections_reused"))
+
+	// Server connections are not recorded.
+	ctx := h.TagConn(t.Context(), &grpcStats.ConnTagInfo{LocalAddr: remote, RemoteAddr: local})
+	h.HandleConn(ctx, &grpcStats.ConnBegin{Client: false})
+	assert.Equal(t, int64(4), count("grpc.io/client/connections_created"))
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sync"
+	"time"
+
+	ocbridge "go.opentelemetry.io/otel/bridge/opencensus"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"google.golang.org/grpc/credentials"
+)
+
+// pushShutdownTimeout bounds the last push and the shutdown of the exporter on Close.
+const pushShutdownTimeout = 10 * time.Second
+
+// PushExportOptions configures pushing the metrics to an OTLP gRPC endpoint.
+type PushExportOptions struct {
+	// Endpoint is the OTLP gRPC endpoint, such as "otel-collector:4317". If empty, the metrics are not pushed.
+	Endpoint string
+	// Interval is the time between pushes.
+	Interval time.Duration
+	// Insecure disables TLS, sending the metrics in plaintext. It should only be used with a local collector.
+	Insecure bool
+	// TLSConfig is the TLS configuration of the connection to the endpoint, unless Insecure.
+	// If nil, the system root certificates are used to verify the endpoint.
+	TLSConfig *tls.Config
+}
+
+// SetPushExport sets an OTLP gRPC endpoint to which the metrics are pushed every interval, in addition
+// to being exposed for scraping. This suits short-lived processes, such as serverless deployments, whose
+// metrics may never be scraped. The metrics of all the started meters of the process are pushed, with the
+// OpenTelemetry OTLP exporter. The push is started by Init and stopped by Close, which pushes the metrics
+// a last time.
+// It must be called before Init.
+func (g *grpcMetrics) SetPushExport(opts PushExportOptions) {
+	if g == nil {
+		return
+	}
+	g.pushOptions = opts
+}
+
+// metricsPusher pushes the metrics to an OTLP endpoint. The provider is guarded by lock, so that Flush
+// does not push concurrently with Close, nor with a provider that is shut down.
+type metricsPusher struct {
+	lock     sync.Mutex
+	provider *sdkmetric.MeterProvider
+}
+
+// forceFlush pushes the metrics, unless the pusher is nil or closed.
+func (p *metricsPusher) forceFlush(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.provider == nil {
+		return nil
+	}
+	return p.provider.ForceFlush(ctx)
+}
+
+// Close stops pushing the metrics, if enabled with SetPushExport, after pushing them a last time.
+func (g *grpcMetrics) Close() error {
+	if g == nil || g.pusher == nil {
+		return nil
+	}
+	g.pusher.lock.Lock()
+	defer g.pusher.lock.Unlock()
+	provider := g.pusher.provider
+	if provider == nil {
+		return nil
+	}
+	g.pusher.provider = nil
+
+	ctx, cancel := context.WithTimeout(context.Background(), pushShutdownTimeout)
+	defer cancel()
+	// Aggregate the pending measurements, so they are part of the last push.
+	if err := g.aggregate(ctx); err != nil {
+		log.Warnf("Failed to flush the metrics before the last push: %v", err)
+	}
+	return provider.Shutdown(ctx)
+}
+
+// newMetricsPusher returns a meter provider that periodically pushes the OpenCensus metrics to the
+// OTLP endpoint of opts, with the app ID as the service name. Pushing starts immediately.
+func newMetricsPusher(opts PushExportOptions, appID string) (*sdkmetric.MeterProvider, error) {
+	if opts.Interval <= 0 {
+		return nil, fmt.Errorf("invalid metrics push interval: %v", opts.Interval)
+	}
+
+	exporterOpts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(opts.Endpoint)}
+	if opts.Insecure {
+		exporterOpts = append(exporterOpts, otlpmetricgrpc.WithInsecure())
+	} else {
+		tlsConfig := opts.TLSConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		exporterOpts = append(exporterOpts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+	}
+
+	exporter, err := otlpmetricgrpc.New(context.Background(), exporterOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the metrics push exporter for %s: %w", opts.Endpoint, err)
+	}
+
+	reader := sdkmetric.NewPeriodicReader(exporter,
+		sdkmetric.WithInterval(opts.Interval),
+		sdkmetric.WithProducer(ocbridge.NewMetricProducer()),
+	)
+	return sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithResource(AppIDResource(appID)),
+	), nil
+}
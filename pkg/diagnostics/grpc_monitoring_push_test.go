@@ -0,0 +1,241 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/dapr/dapr/pkg/config"
+)
+
+// fakeOTLPReceiver is an OTLP metrics service that stores the requests it receives.
+type fakeOTLPReceiver struct {
+	colmetricspb.UnimplementedMetricsServiceServer
+
+	lock     sync.Mutex
+	requests []*colmetricspb.ExportMetricsServiceRequest
+}
+
+func (f *fakeOTLPReceiver) Export(_ context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.requests = append(f.requests, req)
+	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+func (f *fakeOTLPReceiver) received() []*colmetricspb.ExportMetricsServiceRequest {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return append([]*colmetricspb.ExportMetricsServiceRequest(nil), f.requests...)
+}
+
+func startFakeOTLPReceiver(t *testing.T, opts ...grpc.ServerOption) (*fakeOTLPReceiver, string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	receiver := &fakeOTLPReceiver{}
+	server := grpc.NewServer(opts...)
+	colmetricspb.RegisterMetricsServiceServer(server, receiver)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return receiver, lis.Addr().String()
+}
+
+// selfSignedCertificate returns a self-signed certificate for 127.0.0.1, and a pool with it.
+func selfSignedCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "otel-collector"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert}, pool
+}
+
+// findMetric returns the metric with the given name in the last request, or nil.
+func findMetric(reqs []*colmetricspb.ExportMetricsServiceRequest, name string) *metricspb.Metric {
+	if len(reqs) == 0 {
+		return nil
+	}
+	for _, rm := range reqs[len(reqs)-1].GetResourceMetrics() {
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, m := range sm.GetMetrics() {
+				if m.GetName() == name {
+					return m
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func TestPushExport(t *testing.T) {
+	newMetrics := func(t *testing.T, endpoint string, interval time.Duration) *grpcMetrics {
+		m := newGRPCMetrics()
+		m.SetPushExport(PushExportOptions{Endpoint: endpoint, Interval: interval, Insecure: true})
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+		return m
+	}
+
+	t.Run("metrics are pushed periodically", func(t *testing.T) {
+		receiver, endpoint := startFakeOTLPReceiver(t)
+		m := newMetrics(t, endpoint, 10*time.Millisecond)
+		t.Cleanup(func() {
+			m.Close()
+		})
+
+		m.ServerRequestSent(context.Background(), "/dapr.proto.runtime.v1.Dapr/GetState", "OK", 10, 20, time.Now())
+
+		assert.EventuallyWithT(t, func(c *assert.CollectT) {
+			reqs := receiver.received()
+			completed := findMetric(reqs, "grpc.io/server/completed_rpcs")
+			if !assert.NotNil(c, completed) {
+				return
+			}
+			assert.True(c, completed.GetSum().GetIsMonotonic())
+			if assert.Len(c, completed.GetSum().GetDataPoints(), 1) {
+				assert.Equal(c, int64(1), completed.GetSum().GetDataPoints()[0].GetAsInt())
+			}
+
+			latency := findMetric(reqs, "grpc.io/server/server_latency")
+			if assert.NotNil(c, latency) && assert.Len(c, latency.GetHistogram().GetDataPoints(), 1) {
+				assert.Equal(c, uint64(1), latency.GetHistogram().GetDataPoints()[0].GetCount())
+			}
+
+			resource := reqs[len(reqs)-1].GetResourceMetrics()[0].GetResource()
+			assert.Equal(c, "service.name", resource.GetAttributes()[0].GetKey())
+			assert.Equal(c, "test", resource.GetAttributes()[0].GetValue().GetStringValue())
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("metrics are pushed over TLS", func(t *testing.T) {
+		cert, pool := selfSignedCertificate(t)
+		receiver, endpoint := startFakeOTLPReceiver(t, grpc.Creds(credentials.NewServerTLSFromCert(&cert)))
+
+		m := newGRPCMetrics()
+		m.SetPushExport(PushExportOptions{
+			Endpoint:  endpoint,
+			Interval:  time.Hour,
+			TLSConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+		})
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+
+		m.ServerRequestSent(context.Background(), "/dapr.proto.runtime.v1.Dapr/GetState", "OK", 10, 20, time.Now())
+		require.NoError(t, m.Close())
+		assert.NotNil(t, findMetric(receiver.received(), "grpc.io/server/completed_rpcs"))
+	})
+
+	t.Run("close pushes a last time", func(t *testing.T) {
+		receiver, endpoint := startFakeOTLPReceiver(t)
+		m := newMetrics(t, endpoint, time.Hour)
+
+		m.ServerRequestSent(context.Background(), "/dapr.proto.runtime.v1.Dapr/GetState", "OK", 10, 20, time.Now())
+		require.NoError(t, m.Close())
+
+		reqs := receiver.received()
+		require.Len(t, reqs, 1)
+		assert.NotNil(t, findMetric(reqs, "grpc.io/server/completed_rpcs"))
+
+		// Closing again is a no-op.
+		require.NoError(t, m.Close())
+	})
+
+	t.Run("flush pushes without waiting for the interval", func(t *testing.T) {
+		receiver, endpoint := startFakeOTLPReceiver(t)
+		m := newMetrics(t, endpoint, time.Hour)
+		t.Cleanup(func() {
+			m.Close()
+		})
+
+		m.ServerRequestSent(context.Background(), "/dapr.proto.runtime.v1.Dapr/GetState", "OK", 10, 20, time.Now())
+		require.NoError(t, m.Flush(t.Context()))
+
+		reqs := receiver.received()
+		require.Len(t, reqs, 1)
+		assert.NotNil(t, findMetric(reqs, "grpc.io/server/completed_rpcs"))
+	})
+
+	t.Run("flush concurrently with close", func(t *testing.T) {
+		_, endpoint := startFakeOTLPReceiver(t)
+		m := newMetrics(t, endpoint, time.Hour)
+
+		var wg sync.WaitGroup
+		for range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, m.Flush(t.Context()))
+			}()
+		}
+		require.NoError(t, m.Close())
+		wg.Wait()
+
+		// Flushing after close is a no-op.
+		require.NoError(t, m.Flush(t.Context()))
+	})
+
+	t.Run("invalid interval", func(t *testing.T) {
+		m := newGRPCMetrics()
+		m.SetPushExport(PushExportOptions{Endpoint: "localhost:4317", Insecure: true})
+		require.Error(t, m.Init(view.NewMeter(), "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+	})
+
+	t.Run("close without push is a no-op", func(t *testing.T) {
+		m := newMetrics(t, "", 0)
+		require.NoError(t, m.Close())
+
+		var nilMetrics *grpcMetrics
+		require.NoError(t, nilMetrics.Close())
+	})
+}
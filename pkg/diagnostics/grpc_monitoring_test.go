@@ -14,14 +14,30 @@ limitations under the License.
 package diagnostics
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	grpcMetadata "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"github.com/dapr/dapr/pkg/api/grpc/metadata"
 	"github.com/dapr/dapr/pkg/config"
@@ -188,3 +204,1623 @@ func TestStreamingClientInterceptor(t *testing.T) {
 		assert.Equal(t, "grpc_client_status", rows[0].Tags[2].Key.Name())
 	})
 }
+
+func TestMethodLatencyView(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		m := newGRPCMetrics()
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+
+		assert.Nil(t, meter.Find(serverMethodLatencyViewName))
+	})
+
+	t.Run("records latencies per method", func(t *testing.T) {
+		m := newGRPCMetrics()
+		m.EnableMethodLatencyView()
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+
+		start := time.Now().Add(-5 * time.Millisecond)
+		m.ServerRequestSent(t.Context(), "/appv1.Test", "OK", 0, 0, start)
+		m.ServerRequestSent(t.Context(), "/appv1.Test", "Internal", 0, 0, start)
+		m.StreamServerRequestSent(t.Context(), "/appv1.Test", "OK", start)
+
+		rows, err := meter.RetrieveData(serverMethodLatencyViewName)
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.Equal(t, "app_id", rows[0].Tags[0].Key.Name())
+		assert.Equal(t, "grpc_server_method", rows[0].Tags[1].Key.Name())
+		assert.Equal(t, "/appv1.Test", rows[0].Tags[1].Value)
+
+		dist, ok := rows[0].Data.(*view.DistributionData)
+		require.True(t, ok)
+		assert.Equal(t, int64(3), dist.Count)
+		assert.GreaterOrEqual(t, dist.Min, float64(5))
+	})
+}
+
+func TestNilGRPCMetrics(t *testing.T) {
+	var m *grpcMetrics
+
+	t.Run("methods", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			m.EnableMethodLatencyView()
+			require.NoError(t, m.Init(view.NewMeter(), "test", view.Distribution(1, 2)))
+			assert.False(t, m.IsEnabled())
+			m.ServerRequestSent(t.Context(), "/appv1.Test", "OK", 1, 1, time.Now())
+			m.StreamServerRequestSent(t.Context(), "/appv1.Test", "OK", time.Now())
+			m.StreamClientRequestSent(t.Context(), "/appv1.Test", "OK", time.Now())
+			m.ClientRequestReceived(t.Context(), "/appv1.Test", "OK", 1, 1, time.Now())
+			m.AppHealthProbeCompleted(t.Context(), "OK", time.Now())
+		})
+	})
+
+	t.Run("unary server interceptor", func(t *testing.T) {
+		called := false
+		resp, err := m.UnaryServerInterceptor()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Test"}, func(ctx context.Context, req any) (any, error) {
+			called = true
+			return "resp", nil
+		})
+		require.NoError(t, err)
+		assert.True(t, called)
+		assert.Equal(t, "resp", resp)
+	})
+
+	t.Run("unary client interceptor", func(t *testing.T) {
+		called := false
+		err := m.UnaryClientInterceptor()(t.Context(), "/appv1.Test", nil, nil, nil, func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			called = true
+			return nil
+		})
+		require.NoError(t, err)
+		assert.True(t, called)
+	})
+
+	t.Run("streaming server interceptor", func(t *testing.T) {
+		called := false
+		err := m.StreamingServerInterceptor()(nil, &fakeProxyStream{appID: "test"}, &grpc.StreamServerInfo{FullMethod: "/appv1.Test"}, func(srv any, stream grpc.ServerStream) error {
+			called = true
+			return nil
+		})
+		require.NoError(t, err)
+		assert.True(t, called)
+	})
+
+	t.Run("streaming client interceptor", func(t *testing.T) {
+		called := false
+		err := m.StreamingClientInterceptor()(nil, &fakeProxyStream{appID: "test"}, &grpc.StreamServerInfo{FullMethod: "/appv1.Test"}, func(srv any, stream grpc.ServerStream) error {
+			called = true
+			return nil
+		})
+		require.NoError(t, err)
+		assert.True(t, called)
+	})
+}
+
+func TestServerQueueDelay(t *testing.T) {
+	newMetrics := func(t *testing.T) (*grpcMetrics, view.Meter) {
+		m := newGRPCMetrics()
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+		return m, meter
+	}
+
+	t.Run("records delay between arrival and handler", func(t *testing.T) {
+		m, meter := newMetrics(t)
+
+		ctx, err := metadata.SetMetadataInTapHandle(t.Context(), nil)
+		require.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+
+		_, err = m.UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Test"}, func(ctx context.Context, req any) (any, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+
+		rows, err := meter.RetrieveData("grpc.io/server/queue_delay")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.Equal(t, "grpc_server_method", rows[0].Tags[1].Key.Name())
+		assert.Equal(t, "/appv1.Test", rows[0].Tags[1].Value)
+
+		dist, ok := rows[0].Data.(*view.DistributionData)
+		require.True(t, ok)
+		assert.Equal(t, int64(1), dist.Count)
+		assert.GreaterOrEqual(t, dist.Min, float64(10))
+	})
+
+	t.Run("no arrival time, nothing recorded", func(t *testing.T) {
+		m, meter := newMetrics(t)
+
+		_, err := m.UnaryServerInterceptor()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Test"}, func(ctx context.Context, req any) (any, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+
+		rows, err := meter.RetrieveData("grpc.io/server/queue_delay")
+		require.NoError(t, err)
+		assert.Empty(t, rows)
+	})
+}
+
+func TestConnectionSecurityTag(t *testing.T) {
+	newMetrics := func(t *testing.T, enabled bool) (*grpcMetrics, view.Meter) {
+		m := newGRPCMetrics()
+		if enabled {
+			m.EnableConnectionSecurityTag()
+		}
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+		return m, meter
+	}
+
+	invoke := func(t *testing.T, m *grpcMetrics, ctx context.Context) {
+		_, err := m.UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Test"}, func(ctx context.Context, req any) (any, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+	}
+
+	connSecurityTag := func(t *testing.T, meter view.Meter) (string, bool) {
+		rows, err := meter.RetrieveData("grpc.io/server/completed_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		for _, tag := range rows[0].Tags {
+			if tag.Key == KeyConnectionSecurity {
+				return tag.Value, true
+			}
+		}
+		return "", false
+	}
+
+	t.Run("with TLS auth info", func(t *testing.T) {
+		m, meter := newMetrics(t, true)
+		invoke(t, m, peer.NewContext(t.Context(), &peer.Peer{AuthInfo: credentials.TLSInfo{}}))
+
+		val, ok := connSecurityTag(t, meter)
+		require.True(t, ok)
+		assert.Equal(t, "mtls", val)
+	})
+
+	t.Run("without TLS auth info", func(t *testing.T) {
+		m, meter := newMetrics(t, true)
+		invoke(t, m, peer.NewContext(t.Context(), &peer.Peer{}))
+
+		val, ok := connSecurityTag(t, meter)
+		require.True(t, ok)
+		assert.Equal(t, "plaintext", val)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		m, meter := newMetrics(t, false)
+		invoke(t, m, peer.NewContext(t.Context(), &peer.Peer{AuthInfo: credentials.TLSInfo{}}))
+
+		_, ok := connSecurityTag(t, meter)
+		assert.False(t, ok)
+	})
+}
+
+func TestRequestSizeClassTag(t *testing.T) {
+	newMetrics := func(t *testing.T, enabled bool) (*grpcMetrics, view.Meter) {
+		m := newGRPCMetrics()
+		if enabled {
+			m.EnableRequestSizeClassTag()
+		}
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+		return m, meter
+	}
+
+	invoke := func(t *testing.T, m *grpcMetrics, size int) {
+		req := &wrapperspb.BytesValue{Value: make([]byte, size)}
+		_, err := m.UnaryServerInterceptor()(t.Context(), req, &grpc.UnaryServerInfo{FullMethod: "/appv1.Test"}, func(ctx context.Context, req any) (any, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("large payload", func(t *testing.T) {
+		m, meter := newMetrics(t, true)
+		invoke(t, m, 100<<10)
+		invoke(t, m, 10)
+
+		rows, err := meter.RetrieveData("grpc.io/server/server_latency")
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		RequireTagExist(t, rows, NewTag(KeyRequestSizeClass.Name(), RequestSizeClassLarge))
+		RequireTagExist(t, rows, NewTag(KeyRequestSizeClass.Name(), RequestSizeClassSmall))
+
+		// The completed RPCs are not tagged.
+		rows, err = meter.RetrieveData("grpc.io/server/completed_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		RequireTagNotExist(t, rows, NewTag(KeyRequestSizeClass.Name(), RequestSizeClassLarge))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		m, meter := newMetrics(t, false)
+		invoke(t, m, 100<<10)
+
+		rows, err := meter.RetrieveData("grpc.io/server/server_latency")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		RequireTagNotExist(t, rows, NewTag(KeyRequestSizeClass.Name(), RequestSizeClassLarge))
+	})
+
+	t.Run("classes", func(t *testing.T) {
+		assert.Equal(t, RequestSizeClassSmall, RequestSizeClass(0))
+		assert.Equal(t, RequestSizeClassSmall, RequestSizeClass(1<<10-1))
+		assert.Equal(t, RequestSizeClassMedium, RequestSizeClass(1<<10))
+		assert.Equal(t, RequestSizeClassLarge, RequestSizeClass(64<<10))
+		assert.Equal(t, RequestSizeClassXLarge, RequestSizeClass(1<<20))
+	})
+}
+
+func TestServerInterceptorPanic(t *testing.T) {
+	newMetrics := func(t *testing.T) (*grpcMetrics, view.Meter) {
+		m := newGRPCMetrics()
+		m.EnablePanicTag()
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+		return m, meter
+	}
+
+	assertPanicRecorded := func(t *testing.T, meter view.Meter) {
+		rows, err := meter.RetrieveData("grpc.io/server/completed_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		RequireTagExist(t, rows, NewTag(KeyServerStatus.Name(), codes.Internal.String()))
+		RequireTagExist(t, rows, NewTag(KeyPanic.Name(), "true"))
+		assert.Equal(t, int64(1), rows[0].Data.(*view.CountData).Value)
+
+		rows, err = meter.RetrieveData("grpc.io/server/server_latency")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		RequireTagExist(t, rows, NewTag(KeyServerStatus.Name(), codes.Internal.String()))
+	}
+
+	t.Run("unary", func(t *testing.T) {
+		m, meter := newMetrics(t)
+
+		assert.PanicsWithValue(t, "boom", func() {
+			m.UnaryServerInterceptor()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Test"}, func(ctx context.Context, req any) (any, error) {
+				panic("boom")
+			})
+		})
+		assertPanicRecorded(t, meter)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		m, meter := newMetrics(t)
+
+		assert.PanicsWithValue(t, "boom", func() {
+			m.StreamingServerInterceptor()(nil, &fakeProxyStream{appID: "test"}, &grpc.StreamServerInfo{FullMethod: "/appv1.Test"}, func(srv any, stream grpc.ServerStream) error {
+				panic("boom")
+			})
+		})
+		assertPanicRecorded(t, meter)
+	})
+
+	t.Run("no panic", func(t *testing.T) {
+		m, meter := newMetrics(t)
+
+		_, err := m.UnaryServerInterceptor()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Test"}, func(ctx context.Context, req any) (any, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+
+		rows, err := meter.RetrieveData("grpc.io/server/completed_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		RequireTagNotExist(t, rows, NewTag(KeyPanic.Name(), "true"))
+	})
+
+	t.Run("tag disabled", func(t *testing.T) {
+		m := newGRPCMetrics()
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+
+		assert.PanicsWithValue(t, "boom", func() {
+			m.UnaryServerInterceptor()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Test"}, func(ctx context.Context, req any) (any, error) {
+				panic("boom")
+			})
+		})
+
+		rows, err := meter.RetrieveData("grpc.io/server/completed_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		RequireTagExist(t, rows, NewTag(KeyServerStatus.Name(), codes.Internal.String()))
+		RequireTagNotExist(t, rows, NewTag(KeyPanic.Name(), "true"))
+	})
+
+	t.Run("same tags and sampling as other RPCs", func(t *testing.T) {
+		m := newGRPCMetrics()
+		m.EnablePanicTag()
+		m.EnableRequestSizeClassTag()
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+
+		assert.PanicsWithValue(t, "boom", func() {
+			m.UnaryServerInterceptor()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Test method"}, func(ctx context.Context, req any) (any, error) {
+				panic("boom")
+			})
+		})
+
+		rows, err := meter.RetrieveData("grpc.io/server/completed_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		RequireTagExist(t, rows, NewTag(KeyServerMethod.Name(), "/appv1.Test_method"))
+
+		rows, err = meter.RetrieveData("grpc.io/server/server_latency")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		RequireTagExist(t, rows, NewTag(KeyRequestSizeClass.Name(), RequestSizeClassSmall))
+
+		// The latency of unsampled RPCs is not recorded when recording only sampled RPCs.
+		m.SetRecordOnlyWhenSampled(true)
+		assert.PanicsWithValue(t, "boom", func() {
+			m.UnaryServerInterceptor()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Test method"}, func(ctx context.Context, req any) (any, error) {
+				panic("boom")
+			})
+		})
+
+		rows, err = meter.RetrieveData("grpc.io/server/server_latency")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.Equal(t, int64(1), rows[0].Data.(*view.DistributionData).Count)
+	})
+}
+
+func TestSampledTag(t *testing.T) {
+	withSpanContext := func(ctx context.Context, flags trace.TraceFlags) context.Context {
+		return trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{1},
+			SpanID:     trace.SpanID{1},
+			TraceFlags: flags,
+		}))
+	}
+
+	t.Run("values", func(t *testing.T) {
+		assert.Equal(t, SampledTagValueSampled, SampledTagValue(withSpanContext(t.Context(), trace.FlagsSampled)))
+		assert.Equal(t, SampledTagValueUnsampled, SampledTagValue(withSpanContext(t.Context(), 0)))
+		assert.Equal(t, SampledTagValueNone, SampledTagValue(t.Context()))
+	})
+
+	t.Run("completed RPCs", func(t *testing.T) {
+		m := newGRPCMetrics()
+		m.EnableSampledTag()
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+
+		m.ServerRequestSent(withSpanContext(t.Context(), trace.FlagsSampled), "/appv1.Test", "OK", 0, 0, time.Now())
+		m.ServerRequestSent(withSpanContext(t.Context(), 0), "/appv1.Test", "OK", 0, 0, time.Now())
+		m.ServerRequestSent(t.Context(), "/appv1.Test", "OK", 0, 0, time.Now())
+		m.ClientRequestReceived(withSpanContext(t.Context(), trace.FlagsSampled), "/appv1.Test", "OK", 0, 0, time.Now())
+
+		rows, err := meter.RetrieveData("grpc.io/server/completed_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 3)
+		RequireTagExist(t, rows, NewTag(KeySampled.Name(), SampledTagValueSampled))
+		RequireTagExist(t, rows, NewTag(KeySampled.Name(), SampledTagValueUnsampled))
+		RequireTagExist(t, rows, NewTag(KeySampled.Name(), SampledTagValueNone))
+
+		rows, err = meter.RetrieveData("grpc.io/client/completed_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		RequireTagExist(t, rows, NewTag(KeySampled.Name(), SampledTagValueSampled))
+	})
+}
+
+func TestSanitizeMetricLabelValue(t *testing.T) {
+	tests := map[string]string{
+		"/dapr.proto.runtime.v1.Dapr/GetState": "/dapr.proto.runtime.v1.Dapr/GetState",
+		"/my service/Get Item":                 "/my_service/Get_Item",
+		"/svc.v1/Method\n{\"a\"}":              "/svc.v1/Method___a__",
+		"/svc/Métod":                           "/svc/M_tod",
+		"":                                     "",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, SanitizeMetricLabelValue(in), in)
+	}
+
+	long := "/svc/" + strings.Repeat("a", 1000)
+	assert.Len(t, SanitizeMetricLabelValue(long), 256)
+}
+
+func TestStreamingInterceptorSanitizesMethod(t *testing.T) {
+	m := newGRPCMetrics()
+	meter := view.NewMeter()
+	meter.Start()
+	t.Cleanup(func() {
+		meter.Stop()
+	})
+	require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+
+	err := m.StreamingServerInterceptor()(nil, &fakeProxyStream{appID: "test"}, &grpc.StreamServerInfo{FullMethod: "/my service/Get Item"}, func(srv any, stream grpc.ServerStream) error {
+		return nil
+	})
+	require.NoError(t, err)
+
+	rows, err := meter.RetrieveData("grpc.io/server/completed_rpcs")
+	require.NoError(t, err)
+	RequireTagExist(t, rows, NewTag(KeyServerMethod.Name(), "/my_service/Get_Item"))
+}
+
+func TestSubMillisecondLatency(t *testing.T) {
+	m := newGRPCMetrics()
+	meter := view.NewMeter()
+	meter.Start()
+	t.Cleanup(func() {
+		meter.Stop()
+	})
+	require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+
+	m.ServerRequestSent(t.Context(), "/appv1.Test", "OK", 0, 0, time.Now().Add(-500*time.Microsecond))
+
+	rows, err := meter.RetrieveData("grpc.io/server/server_latency")
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+
+	dist, ok := rows[0].Data.(*view.DistributionData)
+	require.True(t, ok)
+	// The latency must not be truncated to whole milliseconds.
+	assert.GreaterOrEqual(t, dist.Min, 0.5)
+	assert.NotEqual(t, math.Trunc(dist.Min), dist.Min)
+}
+
+func TestCanceledStatus(t *testing.T) {
+	m := newGRPCMetrics()
+	m.SetTreatCanceledAsError(false)
+	meter := view.NewMeter()
+	meter.Start()
+	t.Cleanup(func() {
+		meter.Stop()
+	})
+	require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+
+	_, err := m.UnaryServerInterceptor()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Test"}, func(ctx context.Context, req any) (any, error) {
+		return nil, fmt.Errorf("client went away: %w", context.Canceled)
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	rows, err := meter.RetrieveData("grpc.io/server/completed_rpcs")
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.True(t, TagAndValuePresent(rows[0].Tags, NewTag(KeyServerStatus.Name(), codes.Canceled.String())))
+}
+
+func TestStatusCode(t *testing.T) {
+	assert.Equal(t, codes.OK, statusCode(nil))
+	assert.Equal(t, codes.Canceled, statusCode(context.Canceled))
+	assert.Equal(t, codes.Canceled, statusCode(fmt.Errorf("wrapped: %w", context.Canceled)))
+	assert.Equal(t, codes.DeadlineExceeded, statusCode(context.DeadlineExceeded))
+	assert.Equal(t, codes.NotFound, statusCode(status.Error(codes.NotFound, "not found")))
+	assert.Equal(t, codes.Unknown, statusCode(errors.New("boom")))
+}
+
+func TestServerStreamMessageSize(t *testing.T) {
+	m := newGRPCMetrics()
+	meter := view.NewMeter()
+	meter.Start()
+	t.Cleanup(func() {
+		meter.Stop()
+	})
+	require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+
+	sizes := []int{10, 100, 5000}
+	err := m.StreamingServerInterceptor()(nil, &fakeProxyStream{appID: "test"}, &grpc.StreamServerInfo{FullMethod: "/appv1.Test"}, func(srv any, stream grpc.ServerStream) error {
+		require.NoError(t, stream.RecvMsg(&wrapperspb.BytesValue{Value: make([]byte, sizes[0])}))
+		require.NoError(t, stream.SendMsg(&wrapperspb.BytesValue{Value: make([]byte, sizes[1])}))
+		require.NoError(t, stream.SendMsg(&wrapperspb.BytesValue{Value: make([]byte, sizes[2])}))
+		return nil
+	})
+	require.NoError(t, err)
+
+	rows, err := meter.RetrieveData("grpc.io/server/message_size_bytes")
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.True(t, TagAndValuePresent(rows[0].Tags, NewTag(KeyServerMethod.Name(), "/appv1.Test")))
+
+	dist, ok := rows[0].Data.(*view.DistributionData)
+	require.True(t, ok)
+	assert.Equal(t, int64(3), dist.Count)
+	assert.GreaterOrEqual(t, dist.Min, float64(sizes[0]))
+	assert.Less(t, dist.Min, float64(sizes[1]))
+	assert.GreaterOrEqual(t, dist.Max, float64(sizes[2]))
+}
+
+// halfClosingStream is a fakeProxyStream whose client half-closes the stream after sending messages.
+type halfClosingStream struct {
+	fakeProxyStream
+
+	messages int
+}
+
+func (s *halfClosingStream) RecvMsg(m any) error {
+	if s.messages == 0 {
+		return io.EOF
+	}
+	s.messages--
+	return nil
+}
+
+func TestStreamHalfCloseToCompletion(t *testing.T) {
+	newMetrics := func(t *testing.T) (*grpcMetrics, view.Meter) {
+		m := newGRPCMetrics()
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+		return m, meter
+	}
+
+	t.Run("half-close then delayed completion", func(t *testing.T) {
+		m, meter := newMetrics(t)
+		const delay = 50 * time.Millisecond
+		stream := &halfClosingStream{fakeProxyStream: fakeProxyStream{appID: "test"}, messages: 2}
+		err := m.StreamingServerInterceptor()(nil, stream, &grpc.StreamServerInfo{FullMethod: "/appv1.Test"}, func(srv any, stream grpc.ServerStream) error {
+			for {
+				if err := stream.RecvMsg(&wrapperspb.BytesValue{}); errors.Is(err, io.EOF) {
+					break
+				}
+			}
+			// A further RecvMsg does not reset the half-close time.
+			require.ErrorIs(t, stream.RecvMsg(&wrapperspb.BytesValue{}), io.EOF)
+			time.Sleep(delay)
+			return nil
+		})
+		require.NoError(t, err)
+
+		rows, err := meter.RetrieveData("grpc.io/server/stream_half_close_to_completion")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		RequireTagExist(t, rows, NewTag(KeyServerMethod.Name(), "/appv1.Test"))
+
+		dist, ok := rows[0].Data.(*view.DistributionData)
+		require.True(t, ok)
+		assert.Equal(t, int64(1), dist.Count)
+		assert.GreaterOrEqual(t, dist.Min, float64(delay.Milliseconds()))
+	})
+
+	t.Run("stream not half-closed", func(t *testing.T) {
+		m, meter := newMetrics(t)
+		err := m.StreamingServerInterceptor()(nil, &fakeProxyStream{appID: "test"}, &grpc.StreamServerInfo{FullMethod: "/appv1.Test"}, func(srv any, stream grpc.ServerStream) error {
+			return stream.RecvMsg(&wrapperspb.BytesValue{})
+		})
+		require.NoError(t, err)
+
+		rows, err := meter.RetrieveData("grpc.io/server/stream_half_close_to_completion")
+		require.NoError(t, err)
+		assert.Empty(t, rows)
+	})
+}
+
+func TestSlowRequestLogging(t *testing.T) {
+	m := newGRPCMetrics()
+	m.SetSlowRequestThreshold(10 * time.Millisecond)
+	meter := view.NewMeter()
+	meter.Start()
+	t.Cleanup(func() {
+		meter.Stop()
+	})
+	require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+
+	logDest := &bytes.Buffer{}
+	log.EnableJSONOutput(true)
+	log.SetOutput(logDest)
+	t.Cleanup(func() {
+		log.EnableJSONOutput(false)
+		log.SetOutput(os.Stdout)
+	})
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	})
+	ctx := trace.ContextWithSpanContext(t.Context(), sc)
+
+	t.Run("fast request is not logged", func(t *testing.T) {
+		_, err := m.UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Fast"}, func(ctx context.Context, req any) (any, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+		assert.Empty(t, logDest.String())
+	})
+
+	t.Run("slow request is logged", func(t *testing.T) {
+		_, err := m.UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Slow"}, func(ctx context.Context, req any) (any, error) {
+			time.Sleep(15 * time.Millisecond)
+			return nil, status.Error(codes.Internal, "boom")
+		})
+		require.Error(t, err)
+
+		var entry map[string]any
+		require.NoError(t, json.NewDecoder(logDest).Decode(&entry))
+		assert.Equal(t, "Slow gRPC request", entry["msg"])
+		assert.Equal(t, "/appv1.Slow", entry["method"])
+		assert.Equal(t, "Internal", entry["status"])
+		assert.Equal(t, sc.TraceID().String(), entry["traceid"])
+		assert.GreaterOrEqual(t, entry["duration"], float64(15))
+	})
+}
+
+func TestFlush(t *testing.T) {
+	t.Run("records are visible after flush", func(t *testing.T) {
+		m := newGRPCMetrics()
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+
+		for range 10 {
+			m.ServerRequestSent(t.Context(), "/appv1.Test", "OK", 1, 1, time.Now())
+		}
+		require.NoError(t, m.Flush(t.Context()))
+
+		rows, err := meter.RetrieveData("grpc.io/server/completed_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.Equal(t, int64(10), rows[0].Data.(*view.CountData).Value)
+	})
+
+	t.Run("disabled metrics", func(t *testing.T) {
+		require.NoError(t, newGRPCMetrics().Flush(t.Context()))
+
+		var m *grpcMetrics
+		require.NoError(t, m.Flush(t.Context()))
+	})
+}
+
+func TestConstantTags(t *testing.T) {
+	t.Run("tags are added to every measure", func(t *testing.T) {
+		m := newGRPCMetrics()
+		require.NoError(t, m.SetConstantTags(map[string]string{
+			"region":  "us-east",
+			"cluster": "prod-1",
+		}))
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+
+		m.ServerRequestSent(t.Context(), "/appv1.Test", "OK", 1, 1, time.Now())
+		m.ClientRequestReceived(t.Context(), "/appv1.Test", "OK", 1, 1, time.Now())
+
+		for _, name := range []string{"grpc.io/server/completed_rpcs", "grpc.io/server/received_bytes_per_rpc", "grpc.io/client/roundtrip_latency"} {
+			rows, err := meter.RetrieveData(name)
+			require.NoError(t, err)
+			require.Len(t, rows, 1, name)
+			assert.True(t, TagAndValuePresent(rows[0].Tags, NewTag("region", "us-east")), name)
+			assert.True(t, TagAndValuePresent(rows[0].Tags, NewTag("cluster", "prod-1")), name)
+		}
+	})
+
+	t.Run("invalid tag key", func(t *testing.T) {
+		m := newGRPCMetrics()
+		require.Error(t, m.SetConstantTags(map[string]string{"": "us-east"}))
+	})
+}
+
+func TestServerHandlerTimeout(t *testing.T) {
+	newMetrics := func(t *testing.T) (*grpcMetrics, view.Meter) {
+		m := newGRPCMetrics()
+		m.SetServerHandlerTimeout(10 * time.Millisecond)
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+		return m, meter
+	}
+
+	t.Run("handler exceeding the timeout", func(t *testing.T) {
+		m, meter := newMetrics(t)
+
+		var handlerCtxErr error
+		_, err := m.UnaryServerInterceptor()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Test"}, func(ctx context.Context, req any) (any, error) {
+			time.Sleep(30 * time.Millisecond)
+			handlerCtxErr = ctx.Err()
+			return "resp", nil
+		})
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+		require.ErrorIs(t, handlerCtxErr, context.DeadlineExceeded)
+
+		rows, err := meter.RetrieveData("grpc.io/server/handler_timeouts")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.Equal(t, int64(1), rows[0].Data.(*view.CountData).Value)
+
+		rows, err = meter.RetrieveData("grpc.io/server/completed_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.True(t, TagAndValuePresent(rows[0].Tags, NewTag(KeyServerStatus.Name(), codes.DeadlineExceeded.String())))
+	})
+
+	t.Run("handler within the timeout", func(t *testing.T) {
+		m, meter := newMetrics(t)
+
+		resp, err := m.UnaryServerInterceptor()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Test"}, func(ctx context.Context, req any) (any, error) {
+			return "resp", nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "resp", resp)
+
+		rows, err := meter.RetrieveData("grpc.io/server/handler_timeouts")
+		require.NoError(t, err)
+		assert.Empty(t, rows)
+	})
+}
+
+func TestDeadlineOriginTag(t *testing.T) {
+	newMetrics := func(t *testing.T) (*grpcMetrics, view.Meter) {
+		m := newGRPCMetrics()
+		m.SetServerHandlerTimeout(20 * time.Millisecond)
+		m.EnableDeadlineOriginTag()
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+		return m, meter
+	}
+
+	// handler waits for the deadline of its context to expire.
+	handler := func(ctx context.Context, req any) (any, error) {
+		<-ctx.Done()
+		return nil, status.Error(codes.DeadlineExceeded, ctx.Err().Error())
+	}
+
+	t.Run("client deadline", func(t *testing.T) {
+		m, meter := newMetrics(t)
+
+		// The deadline of the incoming context is the one set by the client with grpc-timeout.
+		ctx, cancel := context.WithTimeout(t.Context(), 5*time.Millisecond)
+		defer cancel()
+		_, err := m.UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Test"}, handler)
+		require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+		rows, err := meter.RetrieveData("grpc.io/server/completed_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		RequireTagExist(t, rows, NewTag(KeyDeadlineOrigin.Name(), DeadlineOriginClient))
+	})
+
+	t.Run("server timeout", func(t *testing.T) {
+		m, meter := newMetrics(t)
+
+		_, err := m.UnaryServerInterceptor()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Test"}, handler)
+		require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+		rows, err := meter.RetrieveData("grpc.io/server/completed_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		RequireTagExist(t, rows, NewTag(KeyDeadlineOrigin.Name(), DeadlineOriginServer))
+	})
+
+	t.Run("tag disabled", func(t *testing.T) {
+		m := newGRPCMetrics()
+		m.SetServerHandlerTimeout(20 * time.Millisecond)
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+
+		_, err := m.UnaryServerInterceptor()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Test"}, handler)
+		require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+		rows, err := meter.RetrieveData("grpc.io/server/completed_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		RequireTagNotExist(t, rows, NewTag(KeyDeadlineOrigin.Name(), DeadlineOriginServer))
+	})
+
+	t.Run("other status", func(t *testing.T) {
+		assert.Empty(t, DeadlineOrigin(t.Context(), codes.OK.String()))
+		assert.Empty(t, DeadlineOrigin(t.Context(), codes.Unavailable.String()))
+	})
+}
+
+func TestRecordOnlyWhenSampled(t *testing.T) {
+	m := newGRPCMetrics()
+	m.SetRecordOnlyWhenSampled(true)
+	meter := view.NewMeter()
+	meter.Start()
+	t.Cleanup(func() {
+		meter.Stop()
+	})
+	require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+
+	spanContext := func(flags trace.TraceFlags) trace.SpanContext {
+		return trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+			SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+			TraceFlags: flags,
+		})
+	}
+	sampledCtx := trace.ContextWithSpanContext(t.Context(), spanContext(trace.FlagsSampled))
+	unsampledCtx := trace.ContextWithSpanContext(t.Context(), spanContext(0))
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	}
+	_, err := m.UnaryServerInterceptor()(sampledCtx, nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Sampled"}, handler)
+	require.NoError(t, err)
+	_, err = m.UnaryServerInterceptor()(unsampledCtx, nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Unsampled"}, handler)
+	require.NoError(t, err)
+
+	rows, err := meter.RetrieveData("grpc.io/server/completed_rpcs")
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	RequireTagExist(t, rows, NewTag(KeyServerMethod.Name(), "/appv1.Sampled"))
+	RequireTagExist(t, rows, NewTag(KeyServerMethod.Name(), "/appv1.Unsampled"))
+
+	for _, name := range []string{"grpc.io/server/server_latency", "grpc.io/server/received_bytes_per_rpc", "grpc.io/server/sent_bytes_per_rpc"} {
+		rows, err = meter.RetrieveData(name)
+		require.NoError(t, err)
+		require.Len(t, rows, 1, name)
+		RequireTagExist(t, rows, NewTag(KeyServerMethod.Name(), "/appv1.Sampled"))
+		RequireTagNotExist(t, rows, NewTag(KeyServerMethod.Name(), "/appv1.Unsampled"))
+	}
+}
+
+// failingMeter is a view.Meter whose Nth view registration fails.
+type failingMeter struct {
+	view.Meter
+
+	failAt     int
+	registered int
+}
+
+func (m *failingMeter) Register(views ...*view.View) error {
+	for _, v := range views {
+		m.registered++
+		if m.registered == m.failAt {
+			return errors.New("registration failed")
+		}
+		if err := m.Meter.Register(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestInitRollback(t *testing.T) {
+	meter := view.NewMeter()
+	meter.Start()
+	t.Cleanup(func() {
+		meter.Stop()
+	})
+
+	m := newGRPCMetrics()
+	err := m.Init(&failingMeter{Meter: meter, failAt: 3}, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log))
+	require.Error(t, err)
+	assert.False(t, m.IsEnabled())
+
+	// Views registered before the failure are unregistered.
+	assert.Nil(t, meter.Find("grpc.io/server/server_latency"))
+	assert.Nil(t, meter.Find("grpc.io/server/completed_rpcs"))
+
+	// Recording is a no-op.
+	m.ServerRequestSent(t.Context(), "/appv1.Test", "OK", 1, 1, time.Now())
+	require.NoError(t, m.Flush(t.Context()))
+
+	// Init can be retried.
+	require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+	assert.True(t, m.IsEnabled())
+	assert.NotNil(t, meter.Find("grpc.io/server/server_latency"))
+}
+
+func TestCancellationReason(t *testing.T) {
+	t.Run("deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(t.Context(), time.Millisecond)
+		defer cancel()
+		<-ctx.Done()
+		assert.Equal(t, CancellationReasonDeadline, CancellationReason(ctx, ctx.Err()))
+		assert.Equal(t, CancellationReasonDeadline, CancellationReason(ctx, status.Error(codes.DeadlineExceeded, "deadline")))
+	})
+
+	t.Run("client cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		assert.Equal(t, CancellationReasonClient, CancellationReason(ctx, ctx.Err()))
+		assert.Equal(t, CancellationReasonClient, CancellationReason(ctx, status.Error(codes.Canceled, "canceled")))
+	})
+
+	t.Run("server cancel", func(t *testing.T) {
+		assert.Equal(t, CancellationReasonServer, CancellationReason(t.Context(), status.Error(codes.Canceled, "canceled")))
+		assert.Equal(t, CancellationReasonServer, CancellationReason(t.Context(), context.Canceled))
+	})
+
+	t.Run("server deadline", func(t *testing.T) {
+		assert.Equal(t, CancellationReasonDeadline, CancellationReason(t.Context(), status.Error(codes.DeadlineExceeded, "timeout")))
+	})
+
+	t.Run("not canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		assert.Empty(t, CancellationReason(ctx, nil))
+		assert.Empty(t, CancellationReason(ctx, status.Error(codes.Internal, "boom")))
+	})
+
+	t.Run("recorded by the server interceptor", func(t *testing.T) {
+		m := newGRPCMetrics()
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+
+		handler := func(ctx context.Context, req any) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+
+		deadlineCtx, cancel := context.WithTimeout(t.Context(), time.Millisecond)
+		defer cancel()
+		_, err := m.UnaryServerInterceptor()(deadlineCtx, nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Test"}, handler)
+		require.Error(t, err)
+
+		canceledCtx, cancel := context.WithCancel(t.Context())
+		cancel()
+		_, err = m.UnaryServerInterceptor()(canceledCtx, nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Test"}, handler)
+		require.Error(t, err)
+
+		rows, err := meter.RetrieveData("grpc.io/server/canceled_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		RequireTagExist(t, rows, NewTag(KeyCancellationReason.Name(), CancellationReasonDeadline))
+		RequireTagExist(t, rows, NewTag(KeyCancellationReason.Name(), CancellationReasonClient))
+	})
+}
+
+func TestSecondsLatencyViews(t *testing.T) {
+	newMetrics := func(t *testing.T, keepMilliseconds bool) (*grpcMetrics, view.Meter) {
+		m := newGRPCMetrics()
+		m.EnableSecondsLatencyViews(keepMilliseconds)
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+		return m, meter
+	}
+
+	t.Run("latency is recorded in seconds", func(t *testing.T) {
+		m, meter := newMetrics(t, true)
+		m.ServerRequestSent(t.Context(), "/appv1.Test", "OK", 0, 0, time.Now().Add(-1500*time.Millisecond))
+
+		rows, err := meter.RetrieveData("grpc.io/server/server_latency_seconds")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		data := rows[0].Data.(*view.DistributionData)
+		assert.Equal(t, int64(1), data.Count)
+		assert.InDelta(t, 1.5, data.Mean, 0.1)
+
+		rows, err = meter.RetrieveData("grpc.io/server/server_latency")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.InDelta(t, 1500, rows[0].Data.(*view.DistributionData).Mean, 100)
+	})
+
+	t.Run("milliseconds views are not registered", func(t *testing.T) {
+		m, meter := newMetrics(t, false)
+		m.ClientRequestReceived(t.Context(), "/appv1.Test", "OK", 0, 0, time.Now().Add(-250*time.Millisecond))
+
+		assert.Nil(t, meter.Find("grpc.io/server/server_latency"))
+		assert.Nil(t, meter.Find("grpc.io/client/roundtrip_latency"))
+		assert.Nil(t, meter.Find("grpc.io/healthprobes/roundtrip_latency"))
+
+		rows, err := meter.RetrieveData("grpc.io/client/roundtrip_latency_seconds")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.InDelta(t, 0.25, rows[0].Data.(*view.DistributionData).Mean, 0.1)
+	})
+}
+
+func TestHealthCheckMethods(t *testing.T) {
+	const customMethod = "/grpc.health.v1.Health/Check"
+	t.Cleanup(func() {
+		healthCheckMethodsLock.Lock()
+		delete(healthCheckMethods, customMethod)
+		healthCheckMethodsLock.Unlock()
+	})
+
+	assert.True(t, isHealthCheckMethod(appHealthCheckMethod))
+	assert.False(t, isHealthCheckMethod(customMethod))
+
+	RegisterHealthCheckMethod(customMethod)
+	assert.True(t, isHealthCheckMethod(customMethod))
+	assert.False(t, isHealthCheckMethod("/appv1.Test"))
+
+	t.Run("health checks are recorded as health probes", func(t *testing.T) {
+		m := newGRPCMetrics()
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+
+		invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return nil
+		}
+		for _, method := range []string{appHealthCheckMethod, customMethod, "/appv1.Test"} {
+			require.NoError(t, m.UnaryClientInterceptor()(t.Context(), method, nil, nil, nil, invoker))
+		}
+
+		rows, err := meter.RetrieveData("grpc.io/healthprobes/completed_count")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.Equal(t, int64(2), rows[0].Data.(*view.CountData).Value)
+
+		rows, err = meter.RetrieveData("grpc.io/client/completed_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		RequireTagExist(t, rows, NewTag(KeyClientMethod.Name(), "/appv1.Test"))
+	})
+}
+
+func TestServiceFromFullMethod(t *testing.T) {
+	tests := map[string]string{
+		"/dapr.proto.runtime.v1.Dapr/GetState":                      "dapr.proto.runtime.v1.Dapr",
+		"/dapr.proto.runtime.v1.AppCallbackHealthCheck/HealthCheck": "dapr.proto.runtime.v1.AppCallbackHealthCheck",
+		"/appv1.Test": "",
+		"noslash":     "",
+		"":            "",
+		"/a.b.Svc/":   "a.b.Svc",
+	}
+	for fullMethod, expected := range tests {
+		assert.Equal(t, expected, serviceFromFullMethod(fullMethod), fullMethod)
+	}
+}
+
+func TestCompletedRpcsTags(t *testing.T) {
+	newMetrics := func(t *testing.T, method, service bool) (*grpcMetrics, view.Meter) {
+		m := newGRPCMetrics()
+		m.SetCompletedRpcsTags(method, service)
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+		return m, meter
+	}
+
+	tests := []struct {
+		name            string
+		method, service bool
+	}{
+		{"method only", true, false},
+		{"service only", false, true},
+		{"method and service", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, meter := newMetrics(t, tt.method, tt.service)
+			m.ServerRequestSent(t.Context(), "/dapr.proto.runtime.v1.Dapr/GetState", "OK", 0, 0, time.Now())
+			m.ServerRequestSent(t.Context(), "/dapr.proto.runtime.v1.Dapr/SaveState", "OK", 0, 0, time.Now())
+
+			rows, err := meter.RetrieveData("grpc.io/server/completed_rpcs")
+			require.NoError(t, err)
+			if tt.method {
+				require.Len(t, rows, 2)
+				RequireTagExist(t, rows, NewTag(KeyServerMethod.Name(), "/dapr.proto.runtime.v1.Dapr/GetState"))
+			} else {
+				require.Len(t, rows, 1)
+				assert.Equal(t, int64(2), rows[0].Data.(*view.CountData).Value)
+				RequireTagNotExist(t, rows, NewTag(KeyServerMethod.Name(), "/dapr.proto.runtime.v1.Dapr/GetState"))
+			}
+			if tt.service {
+				RequireTagExist(t, rows, NewTag(KeyServerService.Name(), "dapr.proto.runtime.v1.Dapr"))
+			} else {
+				RequireTagNotExist(t, rows, NewTag(KeyServerService.Name(), "dapr.proto.runtime.v1.Dapr"))
+			}
+		})
+	}
+}
+
+func TestRuntimeVersionTag(t *testing.T) {
+	newMetrics := func(t *testing.T) (*grpcMetrics, view.Meter) {
+		m := newGRPCMetrics()
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+		return m, meter
+	}
+
+	t.Run("version is set", func(t *testing.T) {
+		SetRuntimeVersionTag("1.17.0")
+		t.Cleanup(func() {
+			SetRuntimeVersionTag("")
+		})
+
+		m, meter := newMetrics(t)
+		m.ServerRequestSent(t.Context(), "/appv1.Test", "OK", 0, 0, time.Now())
+		m.ClientRequestReceived(t.Context(), "/appv1.Test", "OK", 0, 0, time.Now())
+
+		for _, name := range []string{"grpc.io/server/completed_rpcs", "grpc.io/client/completed_rpcs"} {
+			rows, err := meter.RetrieveData(name)
+			require.NoError(t, err)
+			require.Len(t, rows, 1, name)
+			RequireTagExist(t, rows, NewTag(KeyRuntimeVersion.Name(), "1.17.0"))
+		}
+
+		rows, err := meter.RetrieveData("grpc.io/server/server_latency")
+		require.NoError(t, err)
+		RequireTagNotExist(t, rows, NewTag(KeyRuntimeVersion.Name(), "1.17.0"))
+	})
+
+	t.Run("version is not set", func(t *testing.T) {
+		m, meter := newMetrics(t)
+		m.ServerRequestSent(t.Context(), "/appv1.Test", "OK", 0, 0, time.Now())
+
+		v := meter.Find("grpc.io/server/completed_rpcs")
+		require.NotNil(t, v)
+		assert.NotContains(t, v.TagKeys, KeyRuntimeVersion)
+	})
+}
+
+func TestWaitForReadyTag(t *testing.T) {
+	newMetrics := func(t *testing.T, enable bool) (*grpcMetrics, view.Meter) {
+		m := newGRPCMetrics()
+		if enable {
+			m.EnableWaitForReadyTag()
+		}
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+		return m, meter
+	}
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return nil
+	}
+
+	t.Run("tag is enabled", func(t *testing.T) {
+		m, meter := newMetrics(t, true)
+		interceptor := m.UnaryClientInterceptor()
+		require.NoError(t, interceptor(t.Context(), "/appv1.Test", nil, nil, nil, invoker))
+		require.NoError(t, interceptor(t.Context(), "/appv1.Test", nil, nil, nil, invoker, grpc.WaitForReady(true)))
+		require.NoError(t, interceptor(t.Context(), "/appv1.Test", nil, nil, nil, invoker, grpc.WaitForReady(true), grpc.WaitForReady(false)))
+
+		for _, name := range []string{"grpc.io/client/completed_rpcs", "grpc.io/client/roundtrip_latency"} {
+			rows, err := meter.RetrieveData(name)
+			require.NoError(t, err)
+			require.Len(t, rows, 2, name)
+			RequireTagExist(t, rows, NewTag(KeyClientWaitForReady.Name(), "true"))
+			RequireTagExist(t, rows, NewTag(KeyClientWaitForReady.Name(), "false"))
+		}
+
+		rows, err := meter.RetrieveData("grpc.io/client/completed_rpcs")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), GetCountValueForObservationWithTagSet(rows, map[tag.Tag]bool{NewTag(KeyClientWaitForReady.Name(), "false"): true}))
+	})
+
+	t.Run("tag is disabled", func(t *testing.T) {
+		m, meter := newMetrics(t, false)
+		require.NoError(t, m.UnaryClientInterceptor()(t.Context(), "/appv1.Test", nil, nil, nil, invoker, grpc.WaitForReady(true)))
+
+		v := meter.Find("grpc.io/client/completed_rpcs")
+		require.NotNil(t, v)
+		assert.NotContains(t, v.TagKeys, KeyClientWaitForReady)
+
+		rows, err := meter.RetrieveData("grpc.io/client/completed_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		RequireTagNotExist(t, rows, NewTag(KeyClientWaitForReady.Name(), "true"))
+	})
+}
+
+func TestSystemMethods(t *testing.T) {
+	const reflectionMethod = "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo"
+
+	newMetrics := func(t *testing.T, mode SystemMethodsMode) (*grpcMetrics, view.Meter) {
+		m := newGRPCMetrics()
+		m.SetSystemMethods(mode)
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+		return m, meter
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	}
+	serverInfo := func(method string) *grpc.UnaryServerInfo {
+		return &grpc.UnaryServerInfo{FullMethod: method}
+	}
+
+	t.Run("system methods are recorded by default", func(t *testing.T) {
+		m, meter := newMetrics(t, SystemMethodsRecord)
+		_, err := m.UnaryServerInterceptor()(t.Context(), nil, serverInfo(reflectionMethod), handler)
+		require.NoError(t, err)
+
+		rows, err := meter.RetrieveData("grpc.io/server/completed_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		RequireTagExist(t, rows, NewTag(KeyServerMethod.Name(), reflectionMethod))
+	})
+
+	t.Run("reflection method is collapsed", func(t *testing.T) {
+		m, meter := newMetrics(t, SystemMethodsCollapse)
+		interceptor := m.UnaryServerInterceptor()
+		_, err := interceptor(t.Context(), nil, serverInfo(reflectionMethod), handler)
+		require.NoError(t, err)
+		_, err = interceptor(t.Context(), nil, serverInfo("/grpc.channelz.v1.Channelz/GetTopChannels"), handler)
+		require.NoError(t, err)
+		_, err = interceptor(t.Context(), nil, serverInfo("/appv1.Test"), handler)
+		require.NoError(t, err)
+
+		rows, err := meter.RetrieveData("grpc.io/server/completed_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		RequireTagExist(t, rows, NewTag(KeyServerMethod.Name(), "/appv1.Test"))
+		RequireTagNotExist(t, rows, NewTag(KeyServerMethod.Name(), reflectionMethod))
+		assert.Equal(t, int64(2), GetCountValueForObservationWithTagSet(rows, map[tag.Tag]bool{NewTag(KeyServerMethod.Name(), SystemMethod): true}))
+	})
+
+	t.Run("system methods are excluded", func(t *testing.T) {
+		m, meter := newMetrics(t, SystemMethodsExclude)
+		_, err := m.UnaryServerInterceptor()(t.Context(), nil, serverInfo(reflectionMethod), handler)
+		require.NoError(t, err)
+		require.NoError(t, m.UnaryClientInterceptor()(t.Context(), reflectionMethod, nil, nil, nil, func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			assert.Equal(t, reflectionMethod, method)
+			return nil
+		}))
+
+		for _, name := range []string{"grpc.io/server/completed_rpcs", "grpc.io/client/completed_rpcs"} {
+			rows, err := meter.RetrieveData(name)
+			require.NoError(t, err)
+			assert.Empty(t, rows, name)
+		}
+	})
+}
+
+func TestTopicAllowList(t *testing.T) {
+	newMetrics := func(t *testing.T, topics []string) (*grpcMetrics, view.Meter) {
+		m := newGRPCMetrics()
+		m.SetTopicAllowList(topics)
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+		return m, meter
+	}
+	publish := func(m *grpcMetrics, topic string) error {
+		_, err := m.UnaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/dapr.proto.runtime.v1.Dapr/PublishEvent"}, func(ctx context.Context, req any) (any, error) {
+			m.SetTopic(ctx, topic)
+			return nil, nil
+		})
+		return err
+	}
+
+	t.Run("allow-listed and other topics", func(t *testing.T) {
+		m, meter := newMetrics(t, []string{"orders"})
+		require.NoError(t, publish(m, "orders"))
+		require.NoError(t, publish(m, "payments"))
+		require.NoError(t, publish(m, "shipments"))
+
+		rows, err := meter.RetrieveData("grpc.io/server/completed_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		RequireTagNotExist(t, rows, NewTag(KeyTopic.Name(), "payments"))
+		assert.Equal(t, int64(1), GetCountValueForObservationWithTagSet(rows, map[tag.Tag]bool{NewTag(KeyTopic.Name(), "orders"): true}))
+		assert.Equal(t, int64(2), GetCountValueForObservationWithTagSet(rows, map[tag.Tag]bool{NewTag(KeyTopic.Name(), "other"): true}))
+	})
+
+	t.Run("RPC without topic", func(t *testing.T) {
+		m, meter := newMetrics(t, []string{"orders"})
+		_, err := m.UnaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Test"}, func(ctx context.Context, req any) (any, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+
+		rows, err := meter.RetrieveData("grpc.io/server/completed_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		RequireTagNotExist(t, rows, NewTag(KeyTopic.Name(), "other"))
+	})
+
+	t.Run("tag is disabled without an allow list", func(t *testing.T) {
+		m, meter := newMetrics(t, nil)
+		require.NoError(t, publish(m, "orders"))
+
+		v := meter.Find("grpc.io/server/completed_rpcs")
+		require.NotNil(t, v)
+		assert.NotContains(t, v.TagKeys, KeyTopic)
+	})
+}
+
+func TestSDKFromUserAgent(t *testing.T) {
+	tests := []struct {
+		ua   string
+		want string
+	}{
+		{"dapr-sdk-dotnet/v1.14.0 grpc-dotnet/2.63.0 (.NET 8.0.6; CLR 8.0.6; net8.0; linux; x64)", "dotnet"},
+		{"dapr-sdk-go/v1.11.0 grpc-go/1.64.0", "go"},
+		{"dapr-sdk-java/v1.12.0 grpc-java-netty/1.64.0", "java"},
+		{"dapr-sdk-js/v3.4.0 grpc-node-js/1.10.9", "js"},
+		{"dapr-sdk-python/1.14.0 grpc-python/1.64.1 grpc-c/41.0.0 (linux; chttp2)", "python"},
+		{"Dapr-SDK-Go/v1.11.0", "go"},
+		{"grpc-go/1.64.0", SDKUnknown},
+		{"dapr-sdk-gopher/v1.0.0", SDKUnknown},
+		{"", SDKUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ua, func(t *testing.T) {
+			assert.Equal(t, tt.want, SDKFromUserAgent(tt.ua))
+		})
+	}
+}
+
+func TestSDKTag(t *testing.T) {
+	newMetrics := func(t *testing.T, enable bool) (*grpcMetrics, view.Meter) {
+		m := newGRPCMetrics()
+		if enable {
+			m.EnableSDKTag()
+		}
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+		return m, meter
+	}
+	call := func(m *grpcMetrics, ua string) error {
+		ctx := grpcMetadata.NewIncomingContext(context.Background(), grpcMetadata.Pairs("user-agent", ua))
+		_, err := m.UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/appv1.Test"}, func(ctx context.Context, req any) (any, error) {
+			return nil, nil
+		})
+		return err
+	}
+
+	t.Run("tag is enabled", func(t *testing.T) {
+		m, meter := newMetrics(t, true)
+		require.NoError(t, call(m, "dapr-sdk-go/v1.11.0 grpc-go/1.64.0"))
+		require.NoError(t, call(m, "grpc-go/1.64.0"))
+
+		rows, err := meter.RetrieveData("grpc.io/server/completed_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		RequireTagExist(t, rows, NewTag(KeySDK.Name(), "go"))
+		RequireTagExist(t, rows, NewTag(KeySDK.Name(), SDKUnknown))
+	})
+
+	t.Run("tag is disabled", func(t *testing.T) {
+		m, meter := newMetrics(t, false)
+		require.NoError(t, call(m, "dapr-sdk-go/v1.11.0 grpc-go/1.64.0"))
+
+		v := meter.Find("grpc.io/server/completed_rpcs")
+		require.NotNil(t, v)
+		assert.NotContains(t, v.TagKeys, KeySDK)
+	})
+}
+
+func TestIsServerError(t *testing.T) {
+	assert.True(t, IsServerError(codes.Internal))
+	assert.True(t, IsServerError(codes.Unavailable))
+	assert.False(t, IsServerError(codes.InvalidArgument))
+	assert.False(t, IsServerError(codes.OK))
+
+	assert.Equal(t, ErrorClassServer, ErrorClass(codes.Internal))
+	assert.Equal(t, ErrorClassClient, ErrorClass(codes.InvalidArgument))
+	assert.Empty(t, ErrorClass(codes.OK))
+}
+
+func TestErrorClassTag(t *testing.T) {
+	newMetrics := func(t *testing.T, enable bool) (*grpcMetrics, view.Meter) {
+		m := newGRPCMetrics()
+		if enable {
+			m.EnableErrorClassTag()
+		}
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+		return m, meter
+	}
+
+	t.Run("tag is enabled", func(t *testing.T) {
+		m, meter := newMetrics(t, true)
+		m.ServerRequestSent(t.Context(), "/appv1.Test", codes.Internal.String(), 0, 0, time.Now())
+		m.ServerRequestSent(t.Context(), "/appv1.Test", codes.InvalidArgument.String(), 0, 0, time.Now())
+		m.ServerRequestSent(t.Context(), "/appv1.Test", codes.OK.String(), 0, 0, time.Now())
+
+		rows, err := meter.RetrieveData("grpc.io/server/completed_rpcs")
+		require.NoError(t, err)
+		require.Len(t, rows, 3)
+		assert.Equal(t, int64(1), GetCountValueForObservationWithTagSet(rows, map[tag.Tag]bool{
+			NewTag(KeyServerStatus.Name(), codes.Internal.String()): true,
+			NewTag(KeyErrorClass.Name(), ErrorClassServer):          true,
+		}))
+		assert.Equal(t, int64(1), GetCountValueForObservationWithTagSet(rows, map[tag.Tag]bool{
+			NewTag(KeyServerStatus.Name(), codes.InvalidArgument.String()): true,
+			NewTag(KeyErrorClass.Name(), ErrorClassClient):                 true,
+		}))
+	})
+
+	t.Run("tag is disabled", func(t *testing.T) {
+		m, meter := newMetrics(t, false)
+		m.ServerRequestSent(t.Context(), "/appv1.Test", codes.Internal.String(), 0, 0, time.Now())
+
+		v := meter.Find("grpc.io/server/completed_rpcs")
+		require.NotNil(t, v)
+		assert.NotContains(t, v.TagKeys, KeyErrorClass)
+	})
+}
+
+func TestContentTypeBucket(t *testing.T) {
+	tests := map[string]string{
+		"application/json":                "application/json",
+		"Application/JSON; charset=utf-8": "application/json",
+		"application/x-protobuf":          "application/x-protobuf",
+		"application/octet-stream":        "application/octet-stream",
+		"application/grpc":                "application/grpc",
+		"application/grpc+proto":          "application/grpc",
+		"text/plain":                      ContentTypeOther,
+		"application/cloudevents+json":    ContentTypeOther,
+		"":                                ContentTypeOther,
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, ContentTypeBucket(in), in)
+	}
+}
+
+func TestPayloadCompressed(t *testing.T) {
+	m := newGRPCMetrics()
+	meter := view.NewMeter()
+	meter.Start()
+	t.Cleanup(func() {
+		meter.Stop()
+	})
+	require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+
+	m.PayloadCompressed(t.Context(), "application/json; charset=utf-8", 1000, 250)
+	// Unknown sizes are not recorded.
+	m.PayloadCompressed(t.Context(), "application/json", 1000, 0)
+	m.PayloadCompressed(t.Context(), "application/json", -1, 250)
+
+	rows, err := meter.RetrieveData("grpc.io/payload/compression_ratio")
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	RequireTagExist(t, rows, NewTag(KeyContentType.Name(), "application/json"))
+	dist, ok := rows[0].Data.(*view.DistributionData)
+	require.True(t, ok)
+	assert.Equal(t, int64(1), dist.Count)
+	assert.InDelta(t, 4.0, dist.Mean, 0.0001)
+}
+
+func TestIsDaprInternalMethod(t *testing.T) {
+	tests := map[string]bool{
+		"/dapr.proto.runtime.v1.Dapr/GetState":                 true,
+		"/dapr.proto.runtime.v1.Dapr/PublishEvent":             true,
+		"/dapr.proto.internals.v1.ServiceInvocation/CallLocal": true,
+		"/myapp.v1.OrderService/CreateOrder":                   false,
+		"/dapr.proto.runtime.v1.AppCallback/OnInvoke":          false,
+		"/grpc.health.v1.Health/Check":                         false,
+		"":                                                     false,
+	}
+	for method, want := range tests {
+		assert.Equal(t, want, IsDaprInternalMethod(method), method)
+	}
+}
+
+func TestSelfLatency(t *testing.T) {
+	newMetrics := func(t *testing.T, enabled bool) (*grpcMetrics, view.Meter) {
+		m := newGRPCMetrics()
+		if enabled {
+			m.EnableSelfLatency()
+		}
+		meter := view.NewMeter()
+		meter.Start()
+		t.Cleanup(func() {
+			meter.Stop()
+		})
+		require.NoError(t, m.Init(meter, "test", config.LoadDefaultConfiguration().GetMetricsSpec().GetLatencyDistribution(log)))
+		return m, meter
+	}
+
+	t.Run("downstream time is excluded", func(t *testing.T) {
+		m, meter := newMetrics(t, true)
+
+		ctx := m.withDownstreamDuration(t.Context())
+		m.AddDownstreamDuration(ctx, 50*time.Millisecond)
+		m.AddDownstreamDuration(ctx, 30*time.Millisecond)
+		m.ServerRequestSent(ctx, "/dapr.proto.runtime.v1.Dapr/InvokeService", "OK", 0, 0, time.Now().Add(-100*time.Millisecond))
+
+		rows, err := meter.RetrieveData("grpc.io/server/self_latency")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		dist, ok := rows[0].Data.(*view.DistributionData)
+		require.True(t, ok)
+		assert.InDelta(t, 20, dist.Mean, 5)
+	})
+
+	t.Run("reported by the handler", func(t *testing.T) {
+		m, meter := newMetrics(t, true)
+
+		_, err := m.UnaryServerInterceptor()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: "/dapr.proto.runtime.v1.Dapr/InvokeService"}, func(ctx context.Context, req any) (any, error) {
+			m.AddDownstreamDuration(ctx, time.Hour)
+			return nil, nil
+		})
+		require.NoError(t, err)
+
+		rows, err := meter.RetrieveData("grpc.io/server/self_latency")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		// The self latency is never negative.
+		assert.Zero(t, rows[0].Data.(*view.DistributionData).Mean)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		m, meter := newMetrics(t, false)
+
+		_, err := m.UnaryServerInterceptor()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: "/dapr.proto.runtime.v1.Dapr/InvokeService"}, func(ctx context.Context, req any) (any, error) {
+			m.AddDownstreamDuration(ctx, time.Millisecond)
+			return nil, nil
+		})
+		require.NoError(t, err)
+		assert.Nil(t, meter.Find("grpc.io/server/self_latency"))
+	})
+}
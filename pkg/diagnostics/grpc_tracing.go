@@ -77,6 +77,12 @@ func handleBaggage(ctx context.Context) (context.Context, string, error) {
 	return grpcMetadata.NewIncomingContext(ctx, md), validBaggage, nil
 }
 
+// OTelSpanNameFromGRPCMethod returns the span name of a gRPC method in the "/package.Service/Method"
+// form, per the OpenTelemetry semantic conventions: "package.Service/Method", without the leading slash.
+func OTelSpanNameFromGRPCMethod(fullMethod string) string {
+	return strings.TrimPrefix(fullMethod, "/")
+}
+
 // GRPCTraceUnaryServerInterceptor sets the trace context or starts the trace client span based on request.
 func GRPCTraceUnaryServerInterceptor(appID string, spec config.TracingSpec) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
@@ -111,7 +117,7 @@ func GRPCTraceUnaryServerInterceptor(appID string, spec config.TracingSpec) grpc
 		}
 
 		ctx = trace.ContextWithRemoteSpanContext(ctx, sc)
-		ctx, span = tracer.Start(ctx, info.FullMethod, spanKind)
+		ctx, span = tracer.Start(ctx, OTelSpanNameFromGRPCMethod(info.FullMethod), spanKind)
 
 		resp, err := handler(ctx, req)
 
@@ -202,7 +208,7 @@ func GRPCTraceStreamServerInterceptor(appID string, spec config.TracingSpec) grp
 		// Overwrite context
 		sc, _ := SpanContextFromIncomingGRPCMetadata(ctx)
 		ctx = trace.ContextWithRemoteSpanContext(ctx, sc)
-		ctx, span = tracer.Start(ctx, info.FullMethod, spanKind)
+		ctx, span = tracer.Start(ctx, OTelSpanNameFromGRPCMethod(info.FullMethod), spanKind)
 		wrapped := grpcMiddleware.WrapServerStream(ss)
 		wrapped.WrappedContext = ctx
 
@@ -368,7 +374,7 @@ func spanAttributesMapFromGRPC(appID string, req any, rpcMethod string) map[stri
 			m[diagConsts.DaprAPIInvokeMethod] = s.GetMessage().GetMethod()
 		} else {
 			m[diagConsts.DaprAPISpanNameInternal] = "CallActor/" + s.GetActor().GetActorType() + "/" + s.GetMessage().GetMethod()
-			m[diagConsts.DaprAPIActorTypeID] = s.GetActor().GetActorType() + "." + s.GetActor().GetActorId()
+			m[diagConsts.DaprAPIActorTypeID] = ActorSpanAttributeValue(s.GetActor().GetActorType(), s.GetActor().GetActorId())
 		}
 
 	// Dapr APIs
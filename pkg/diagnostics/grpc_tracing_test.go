@@ -70,6 +70,11 @@ func TestSpanAttributesMapFromGRPC(t *testing.T) {
 	}
 }
 
+func TestOTelSpanNameFromGRPCMethod(t *testing.T) {
+	assert.Equal(t, "dapr.proto.runtime.v1.Dapr/GetState", OTelSpanNameFromGRPCMethod("/dapr.proto.runtime.v1.Dapr/GetState"))
+	assert.Equal(t, "dapr.proto.runtime.v1.Dapr/GetState", OTelSpanNameFromGRPCMethod("dapr.proto.runtime.v1.Dapr/GetState"))
+}
+
 func TestUserDefinedMetadata(t *testing.T) {
 	md := grpcMetadata.MD{
 		"dapr-userdefined-1": []string{"value1"},
@@ -54,6 +54,14 @@ var defaultSizeDistribution = view.Distribution(1<<10, 2<<10, 4<<10, 16<<10, 64<
 // recorded a payload that exceeds the configured gRPC max body size.
 var payloadRatioDistribution = view.Distribution(0.1, 0.25, 0.5, 0.75, 0.9, 0.95, 0.99, 1.0, 1.5, 2.0)
 
+// metadataCountDistribution buckets the number of metadata entries of a request,
+// so that clients sending an unusually high number of headers stand out.
+var metadataCountDistribution = view.Distribution(0, 5, 10, 20, 30, 50, 75, 100, 150, 200)
+
+// invocationChainDepthDistribution buckets the number of apps in the invocation chain of a request,
+// up to the maximum length of the chain.
+var invocationChainDepthDistribution = view.Distribution(0, 1, 2, 3, 4, 5, 6, 8, 10, 12, 16)
+
 // InitMetrics initializes metrics.
 func InitMetrics(meter view.Meter, appID, namespace string, metricSpec config.MetricSpec) error {
 	meter.Start()
@@ -63,6 +71,18 @@ func InitMetrics(meter view.Meter, appID, namespace string, metricSpec config.Me
 		return err
 	}
 
+	if push := metricSpec.Push; push != nil && push.EndpointAddress != "" {
+		interval, err := push.GetInterval()
+		if err != nil {
+			return err
+		}
+		DefaultGRPCMonitoring.SetPushExport(PushExportOptions{
+			Endpoint: push.EndpointAddress,
+			Interval: interval,
+			Insecure: !push.GetIsSecure(),
+		})
+	}
+
 	if err := DefaultGRPCMonitoring.Init(meter, appID, latencyDistribution); err != nil {
 		return err
 	}
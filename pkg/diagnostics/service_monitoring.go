@@ -33,6 +33,8 @@ var (
 	targetKey           = tag.MustNewKey("target")
 	typeKey             = tag.MustNewKey("type")
 	categoryKey         = tag.MustNewKey("category")
+	protocolKey         = tag.MustNewKey("protocol")
+	protocolVersionKey  = tag.MustNewKey("protocol_version")
 )
 
 const (
@@ -79,6 +81,9 @@ type serviceMetrics struct {
 	// Service Invocation metrics
 	serviceInvocationRequestSentTotal        *stats.Int64Measure
 	serviceInvocationRequestReceivedTotal    *stats.Int64Measure
+	serviceInvocationRequestOriginTotal      *stats.Int64Measure
+	serviceInvocationRequestMetadataCount    *stats.Int64Measure
+	serviceInvocationChainDepth              *stats.Int64Measure
 	serviceInvocationResponseSentTotal       *stats.Int64Measure
 	serviceInvocationResponseReceivedTotal   *stats.Int64Measure
 	serviceInvocationResponseReceivedLatency *stats.Float64Measure
@@ -209,6 +214,18 @@ func newServiceMetrics() *serviceMetrics {
 			"runtime/service_invocation/req_recv_total",
 			"The number of requests received via service invocation.",
 			stats.UnitDimensionless),
+		serviceInvocationRequestOriginTotal: stats.Int64(
+			"runtime/service_invocation/req_recv_by_protocol_total",
+			"The number of requests received via service invocation, by the protocol of the originating client.",
+			stats.UnitDimensionless),
+		serviceInvocationRequestMetadataCount: stats.Int64(
+			"runtime/service_invocation/req_recv_metadata_count",
+			"The number of metadata entries of the requests received via service invocation.",
+			stats.UnitDimensionless),
+		serviceInvocationChainDepth: stats.Int64(
+			"runtime/service_invocation/req_recv_chain_depth",
+			"The number of apps in the invocation chain of the requests received via service invocation.",
+			stats.UnitDimensionless),
 		serviceInvocationResponseSentTotal: stats.Int64(
 			"runtime/service_invocation/res_sent_total",
 			"The number of responses sent via service invocation.",
@@ -267,6 +284,9 @@ func (s *serviceMetrics) Init(meter view.Meter, appID string, latencyDistributio
 
 		diagUtils.NewMeasureView(s.serviceInvocationRequestSentTotal, []tag.Key{appIDKey, destinationAppIDKey, typeKey}, view.Count()),
 		diagUtils.NewMeasureView(s.serviceInvocationRequestReceivedTotal, []tag.Key{appIDKey, sourceAppIDKey}, view.Count()),
+		diagUtils.NewMeasureView(s.serviceInvocationRequestOriginTotal, []tag.Key{appIDKey, protocolKey, protocolVersionKey}, view.Count()),
+		diagUtils.NewMeasureView(s.serviceInvocationRequestMetadataCount, []tag.Key{appIDKey, protocolKey}, metadataCountDistribution),
+		diagUtils.NewMeasureView(s.serviceInvocationChainDepth, []tag.Key{appIDKey, sourceAppIDKey}, invocationChainDepthDistribution),
 		diagUtils.NewMeasureView(s.serviceInvocationResponseSentTotal, []tag.Key{appIDKey, destinationAppIDKey, statusKey}, view.Count()),
 		diagUtils.NewMeasureView(s.serviceInvocationResponseReceivedTotal, []tag.Key{appIDKey, sourceAppIDKey, statusKey, typeKey}, view.Count()),
 		diagUtils.NewMeasureView(s.serviceInvocationResponseReceivedLatency, []tag.Key{appIDKey, sourceAppIDKey, statusKey}, latencyDistribution),
@@ -602,6 +622,53 @@ func (s *serviceMetrics) ServiceInvocationRequestReceived(sourceAppID string) {
 	}
 }
 
+// ServiceInvocationRequestOrigin records the protocol, "http" or "grpc", of the client that originated
+// a service invocation request received, and the HTTP protocol version, such as "HTTP/1.1" or "HTTP/2",
+// over which it was received.
+func (s *serviceMetrics) ServiceInvocationRequestOrigin(protocol, protocolVersion string) {
+	if s.enabled {
+		stats.RecordWithOptions(
+			s.ctx,
+			stats.WithRecorder(s.meter),
+			stats.WithTags(diagUtils.WithTags(
+				s.serviceInvocationRequestOriginTotal.Name(),
+				appIDKey, s.appID,
+				protocolKey, protocol,
+				protocolVersionKey, protocolVersion)...),
+			stats.WithMeasurements(s.serviceInvocationRequestOriginTotal.M(1)))
+	}
+}
+
+// ServiceInvocationRequestMetadataCount records the number of metadata entries of a service invocation
+// request received, by the protocol, "http" or "grpc", of the client that originated it.
+func (s *serviceMetrics) ServiceInvocationRequestMetadataCount(protocol string, count int) {
+	if s.enabled {
+		stats.RecordWithOptions(
+			s.ctx,
+			stats.WithRecorder(s.meter),
+			stats.WithTags(diagUtils.WithTags(
+				s.serviceInvocationRequestMetadataCount.Name(),
+				appIDKey, s.appID,
+				protocolKey, protocol)...),
+			stats.WithMeasurements(s.serviceInvocationRequestMetadataCount.M(int64(count))))
+	}
+}
+
+// ServiceInvocationChainDepth records the number of apps in the invocation chain of a service invocation
+// request received, by the app ID of the caller, to measure how deep chains of invocations get.
+func (s *serviceMetrics) ServiceInvocationChainDepth(sourceAppID string, depth int) {
+	if s.enabled {
+		stats.RecordWithOptions(
+			s.ctx,
+			stats.WithRecorder(s.meter),
+			stats.WithTags(diagUtils.WithTags(
+				s.serviceInvocationChainDepth.Name(),
+				appIDKey, s.appID,
+				sourceAppIDKey, sourceAppID)...),
+			stats.WithMeasurements(s.serviceInvocationChainDepth.M(int64(depth))))
+	}
+}
+
 // ServiceInvocationResponseSent records the number of service invocation responses sent.
 func (s *serviceMetrics) ServiceInvocationResponseSent(destinationAppID string, status int32) {
 	if s.enabled {
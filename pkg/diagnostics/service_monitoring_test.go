@@ -5,7 +5,9 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
 
 	"github.com/dapr/dapr/pkg/config"
 )
@@ -58,6 +60,58 @@ func TestServiceInvocation(t *testing.T) {
 		allTagsPresent(t, v, viewData[0].Tags)
 	})
 
+	t.Run("record service invocation request origin", func(t *testing.T) {
+		s, meter := servicesMetrics()
+		t.Cleanup(func() { meter.Stop() })
+
+		s.ServiceInvocationRequestOrigin("http", "HTTP/1.1")
+		s.ServiceInvocationRequestOrigin("grpc", "HTTP/2")
+
+		viewData, _ := meter.RetrieveData("runtime/service_invocation/req_recv_by_protocol_total")
+		v := meter.Find("runtime/service_invocation/req_recv_by_protocol_total")
+
+		require.Len(t, viewData, 2)
+		allTagsPresent(t, v, viewData[0].Tags)
+		assert.Equal(t, int64(1), GetCountValueForObservationWithTagSet(viewData, map[tag.Tag]bool{NewTag(protocolKey.Name(), "http"): true}))
+		assert.Equal(t, int64(1), GetCountValueForObservationWithTagSet(viewData, map[tag.Tag]bool{NewTag(protocolKey.Name(), "grpc"): true}))
+		assert.Equal(t, int64(1), GetCountValueForObservationWithTagSet(viewData, map[tag.Tag]bool{NewTag(protocolVersionKey.Name(), "HTTP/1.1"): true}))
+		assert.Equal(t, int64(1), GetCountValueForObservationWithTagSet(viewData, map[tag.Tag]bool{NewTag(protocolVersionKey.Name(), "HTTP/2"): true}))
+	})
+
+	t.Run("record service invocation chain depth", func(t *testing.T) {
+		s, meter := servicesMetrics()
+		t.Cleanup(func() { meter.Stop() })
+
+		s.ServiceInvocationChainDepth("testAppId2", 4)
+
+		viewData, _ := meter.RetrieveData("runtime/service_invocation/req_recv_chain_depth")
+		v := meter.Find("runtime/service_invocation/req_recv_chain_depth")
+
+		require.Len(t, viewData, 1)
+		allTagsPresent(t, v, viewData[0].Tags)
+		RequireTagExist(t, viewData, NewTag(sourceAppIDKey.Name(), "testAppId2"))
+		data := viewData[0].Data.(*view.DistributionData)
+		assert.Equal(t, int64(1), data.Count)
+		assert.InDelta(t, 4.0, data.Max, 0)
+	})
+
+	t.Run("record service invocation request metadata count", func(t *testing.T) {
+		s, meter := servicesMetrics()
+		t.Cleanup(func() { meter.Stop() })
+
+		s.ServiceInvocationRequestMetadataCount("grpc", 12)
+
+		viewData, _ := meter.RetrieveData("runtime/service_invocation/req_recv_metadata_count")
+		v := meter.Find("runtime/service_invocation/req_recv_metadata_count")
+
+		require.Len(t, viewData, 1)
+		allTagsPresent(t, v, viewData[0].Tags)
+		RequireTagExist(t, viewData, NewTag(protocolKey.Name(), "grpc"))
+		data := viewData[0].Data.(*view.DistributionData)
+		assert.Equal(t, int64(1), data.Count)
+		assert.InDelta(t, 12.0, data.Max, 0)
+	})
+
 	t.Run("record service invocation response sent", func(t *testing.T) {
 		s, meter := servicesMetrics()
 		t.Cleanup(func() { meter.Stop() })
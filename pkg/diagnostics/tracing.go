@@ -15,13 +15,18 @@ package diagnostics
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"strings"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/status"
 
 	"github.com/dapr/dapr/pkg/config"
 	diagConsts "github.com/dapr/dapr/pkg/diagnostics/consts"
@@ -32,6 +37,8 @@ const (
 	daprHeaderPrefix    = "dapr-"
 	daprHeaderBinSuffix = "-bin"
 	tracerName          = "dapr-diagnostics"
+
+	actorSpanAttributeSeparator = "."
 )
 
 var tracer trace.Tracer = otel.Tracer(tracerName)
@@ -111,6 +118,63 @@ func SpanContextFromW3CString(h string) (sc trace.SpanContext, ok bool) {
 	return sc, true
 }
 
+// ValidateTraceparent validates a traceparent header value per the W3C Trace Context spec,
+// returning an error that describes why it is invalid.
+// See https://www.w3.org/TR/trace-context/#traceparent-header
+func ValidateTraceparent(tp string) error {
+	if tp == "" {
+		return errors.New("traceparent is empty")
+	}
+	sections := strings.Split(tp, "-")
+	if len(sections) < 4 {
+		return fmt.Errorf("traceparent has %d fields, expected 4", len(sections))
+	}
+
+	if len(sections[0]) != 2 || !isLowerHex(sections[0]) {
+		return fmt.Errorf("traceparent version %q is not 2 lowercase hex characters", sections[0])
+	}
+	ver, _ := hex.DecodeString(sections[0])
+	if version := int(ver[0]); version > diagConsts.MaxVersion {
+		return fmt.Errorf("traceparent version %q is invalid", sections[0])
+	} else if version == diagConsts.SupportedVersion && len(sections) != 4 {
+		return fmt.Errorf("traceparent has %d fields, expected 4 for version %q", len(sections), sections[0])
+	}
+
+	if len(sections[1]) != 32 || !isLowerHex(sections[1]) {
+		return fmt.Errorf("traceparent trace-id %q is not 32 lowercase hex characters", sections[1])
+	}
+	if strings.Trim(sections[1], "0") == "" {
+		return errors.New("traceparent trace-id is all zeros")
+	}
+
+	if len(sections[2]) != 16 || !isLowerHex(sections[2]) {
+		return fmt.Errorf("traceparent parent-id %q is not 16 lowercase hex characters", sections[2])
+	}
+	if strings.Trim(sections[2], "0") == "" {
+		return errors.New("traceparent parent-id is all zeros")
+	}
+
+	if len(sections[3]) != 2 || !isLowerHex(sections[3]) {
+		return fmt.Errorf("traceparent trace-flags %q is not 2 lowercase hex characters", sections[3])
+	}
+
+	if _, ok := SpanContextFromW3CString(tp); !ok {
+		return errors.New("traceparent is invalid")
+	}
+	return nil
+}
+
+// isLowerHex returns true if s only contains lowercase hex characters.
+func isLowerHex(s string) bool {
+	for i := range len(s) {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
 // TraceStateFromW3CString extracts a span tracestate from given string which got earlier from TraceStateFromW3CString format.
 func TraceStateFromW3CString(h string) *trace.TraceState {
 	if h == "" {
@@ -127,6 +191,59 @@ func TraceStateFromW3CString(h string) *trace.TraceState {
 	return &ts
 }
 
+// TraceStateUpsert adds or updates the key=value list-member in the W3C tracestate string ts.
+// Per the W3C spec the updated member is moved to the beginning of the list, and the
+// right-most (oldest) members are evicted when the list would exceed 32 members or
+// diagConsts.MaxTracestateLen characters. If key or value is invalid, ts is returned unchanged.
+func TraceStateUpsert(ts string, key, value string) string {
+	state := TraceStateFromW3CString(ts)
+	updated, err := state.Insert(key, value)
+	if err != nil {
+		return ts
+	}
+
+	for updated.Len() > 1 && len(updated.String()) > diagConsts.MaxTracestateLen {
+		var oldest string
+		updated.Walk(func(k, _ string) bool {
+			oldest = k
+			return true
+		})
+		updated = updated.Delete(oldest)
+	}
+	if len(updated.String()) > diagConsts.MaxTracestateLen {
+		return ts
+	}
+
+	return updated.String()
+}
+
+// TraceStateGet returns the value associated with key in the W3C tracestate string ts.
+func TraceStateGet(ts string, key string) (string, bool) {
+	state := TraceStateFromW3CString(ts)
+	var (
+		value string
+		found bool
+	)
+	state.Walk(func(k, v string) bool {
+		if k == key {
+			value, found = v, true
+			return false
+		}
+		return true
+	})
+	return value, found
+}
+
+// AppIDResource returns an OpenTelemetry resource with the app ID as the service.name attribute, so the
+// spans of a tracer provider registered with it are attributed to the app. This is the trace counterpart
+// of the app ID tag on metrics.
+func AppIDResource(appID string) *resource.Resource {
+	return resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceNameKey.String(appID),
+	)
+}
+
 // AddAttributesToSpan adds the given attributes in the span.
 func AddAttributesToSpan(span trace.Span, attributes map[string]string) {
 	if span == nil {
@@ -145,6 +262,19 @@ func AddAttributesToSpan(span trace.Span, attributes map[string]string) {
 	}
 }
 
+// AddRetryAttemptEvent adds an event to the span in ctx recording a retry attempt, with the attempt
+// number and the gRPC status code of the error that caused the retry.
+func AddRetryAttemptEvent(ctx context.Context, attempt int, lastErr error) {
+	span := diagUtils.SpanFromContext(ctx)
+	if span == nil || !span.IsRecording() {
+		return
+	}
+	span.AddEvent(diagConsts.RetryAttemptSpanEventName, trace.WithAttributes(
+		attribute.Int(diagConsts.RetryAttemptSpanAttributeKey, attempt),
+		attribute.String(diagConsts.RetryAttemptErrorCodeAttributeKey, status.Code(lastErr).String()),
+	))
+}
+
 // ConstructInputBindingSpanAttributes creates span attributes for InputBindings.
 func ConstructInputBindingSpanAttributes(bindingName, url string) map[string]string {
 	return map[string]string{
@@ -163,6 +293,43 @@ func ConstructSubscriptionSpanAttributes(topic string) map[string]string {
 	}
 }
 
+// BindingSpanAttributes creates span attributes for a binding invocation, in the given direction,
+// diagConsts.BindingDirectionInput or diagConsts.BindingDirectionOutput, so that input and output
+// binding invocations are traced consistently.
+func BindingSpanAttributes(bindingName, operation, direction string) map[string]string {
+	m := map[string]string{
+		diagConsts.MessagingSystemSpanAttributeKey:      diagConsts.BindingBuildingBlockType,
+		diagConsts.MessagingDestinationSpanAttributeKey: bindingName,
+		diagConsts.DaprBindingDirectionAttributeKey:     direction,
+	}
+	if operation != "" {
+		m[diagConsts.MessagingOperationSpanAttributeKey] = operation
+	}
+	return m
+}
+
+// ActorSpanAttributeValue returns the value of the diagConsts.DaprAPIActorTypeID span attribute for the given actor.
+func ActorSpanAttributeValue(actorType, actorID string) string {
+	return actorType + actorSpanAttributeSeparator + actorID
+}
+
+// HashedActorSpanAttributeValue is like ActorSpanAttributeValue, but replaces the actor ID with a hash of it,
+// for actor IDs that must not be exported in traces. Equal IDs produce equal hashes, so traces remain searchable.
+func HashedActorSpanAttributeValue(actorType, actorID string) string {
+	sum := sha256.Sum256([]byte(actorID))
+	return ActorSpanAttributeValue(actorType, hex.EncodeToString(sum[:8]))
+}
+
+// ParseActorSpanAttributeValue parses a value returned by ActorSpanAttributeValue into the actor type and ID.
+// Actor types are assumed not to contain the separator, while actor IDs may.
+func ParseActorSpanAttributeValue(val string) (actorType string, actorID string, ok bool) {
+	actorType, actorID, ok = strings.Cut(val, actorSpanAttributeSeparator)
+	if !ok || actorType == "" || actorID == "" {
+		return "", "", false
+	}
+	return actorType, actorID, true
+}
+
 // StartInternalCallbackSpan starts trace span for internal callback such as input bindings and pubsub subscription.
 func StartInternalCallbackSpan(ctx context.Context, spanName string, parent trace.SpanContext, spec *config.TracingSpec) (context.Context, trace.Span) {
 	if spec == nil || !diagUtils.IsTracingEnabled(spec.SamplingRate) {
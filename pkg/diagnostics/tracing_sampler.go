@@ -1,12 +1,98 @@
 package diagnostics
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
 
 	diagUtils "github.com/dapr/dapr/pkg/diagnostics/utils"
 )
 
+const (
+	// otelTraceStateKey is the tracestate key of the OpenTelemetry list-member.
+	otelTraceStateKey = "ot"
+	// otelThresholdKey is the sub-key of the rejection threshold in the OpenTelemetry tracestate value.
+	otelThresholdKey = "th"
+	// otelThresholdMaxLen is the number of hex digits of a full 56-bit rejection threshold.
+	otelThresholdMaxLen = 14
+)
+
 func NewDaprTraceSampler(samplingRateString string) sdktrace.Sampler {
 	samplingRate := diagUtils.GetTraceSamplingRate(samplingRateString)
 	return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(samplingRate))
 }
+
+// MethodBasedSampler is an OpenTelemetry sampler that samples the spans of each method with its own
+// rate, such as 1 for critical methods and 0.01 for noisy ones, and the spans of the other methods
+// with a default rate. Like the TraceIDRatioBased sampler, the decision is derived from the trace ID,
+// so it is consistent across the hops of a trace that use the same rate.
+type MethodBasedSampler struct {
+	defaultRate float64
+	methodRates map[string]float64
+}
+
+// NewMethodBasedSampler returns a sampler that follows the sampling decision of the parent span, if any,
+// and otherwise samples with a MethodBasedSampler with the sampling rates of methodRates, keyed by gRPC
+// method, in either the "/package.Service/Method" form or the span name form returned by
+// OTelSpanNameFromGRPCMethod, and defaultRate for the other methods.
+func NewMethodBasedSampler(defaultRate float64, methodRates map[string]float64) sdktrace.Sampler {
+	rates := make(map[string]float64, len(methodRates))
+	for method, rate := range methodRates {
+		rates[OTelSpanNameFromGRPCMethod(method)] = rate
+	}
+	return sdktrace.ParentBased(&MethodBasedSampler{
+		defaultRate: defaultRate,
+		methodRates: rates,
+	})
+}
+
+// ShouldSample implements sdktrace.Sampler, with the rate of the method that is the name of the span.
+func (s *MethodBasedSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	rate, ok := s.methodRates[OTelSpanNameFromGRPCMethod(p.Name)]
+	if !ok {
+		rate = s.defaultRate
+	}
+	decision := sdktrace.Drop
+	if diagUtils.ShouldSample(p.TraceID, rate) {
+		decision = sdktrace.RecordAndSample
+	}
+	return sdktrace.SamplingResult{
+		Decision:   decision,
+		Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+	}
+}
+
+// Description implements sdktrace.Sampler.
+func (s *MethodBasedSampler) Description() string {
+	return fmt.Sprintf("MethodBasedSampler{default:%g,methods:%d}", s.defaultRate, len(s.methodRates))
+}
+
+// AdjustedSamplingRatioFromTraceState returns the sampling ratio with which an upstream span was sampled,
+// from the rejection threshold ("th") of the OpenTelemetry list-member of the W3C tracestate string ts,
+// such as "ot=th:c" for a ratio of 0.25. It returns false if ts has no valid threshold.
+func AdjustedSamplingRatioFromTraceState(ts string) (float64, bool) {
+	value, ok := TraceStateGet(ts, otelTraceStateKey)
+	if !ok {
+		return 0, false
+	}
+	for _, field := range strings.Split(value, ";") {
+		key, th, ok := strings.Cut(field, ":")
+		if !ok || key != otelThresholdKey {
+			continue
+		}
+		if th == "" || len(th) > otelThresholdMaxLen || strings.ToLower(th) != th {
+			return 0, false
+		}
+		// The threshold is the most significant hex digits of a 56-bit value, with trailing zeros removed.
+		threshold, err := strconv.ParseUint(th+strings.Repeat("0", otelThresholdMaxLen-len(th)), 16, 64)
+		if err != nil {
+			return 0, false
+		}
+		const maxThreshold = 1 << 56
+		return float64(maxThreshold-threshold) / maxThreshold, true
+	}
+	return 0, false
+}
@@ -16,8 +16,10 @@ package diagnostics
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/rand"
+	"strings"
 	"sync"
 	"testing"
 
@@ -28,8 +30,11 @@ import (
 	diagConsts "github.com/dapr/dapr/pkg/diagnostics/consts"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestSpanContextToW3CString(t *testing.T) {
@@ -88,6 +93,39 @@ func TestSpanContextFromW3CString(t *testing.T) {
 	})
 }
 
+func TestValidateTraceparent(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		require.NoError(t, ValidateTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+		// Future versions may have more fields.
+		require.NoError(t, ValidateTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"))
+	})
+
+	tests := []struct {
+		name     string
+		tp       string
+		errorMsg string
+	}{
+		{"empty", "", "traceparent is empty"},
+		{"missing fields", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", "has 3 fields"},
+		{"bad version", "0x-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "version \"0x\""},
+		{"forbidden version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "version \"ff\" is invalid"},
+		{"extra fields for version 00", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", "expected 4 for version"},
+		{"uppercase trace id", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", "trace-id"},
+		{"short trace id", "00-4bf92f3577b34da6-00f067aa0ba902b7-01", "trace-id"},
+		{"all-zero trace id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", "trace-id is all zeros"},
+		{"bad parent id", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902bz-01", "parent-id"},
+		{"all-zero parent id", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", "parent-id is all zeros"},
+		{"bad flags", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1", "trace-flags"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTraceparent(tt.tp)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
 func TestTraceStateFromW3CString(t *testing.T) {
 	t.Run("empty Tracestate", func(t *testing.T) {
 		ts := trace.TraceState{}
@@ -114,6 +152,173 @@ func TestTraceStateFromW3CString(t *testing.T) {
 	})
 }
 
+func TestTraceStateUpsert(t *testing.T) {
+	t.Run("add entry", func(t *testing.T) {
+		assert.Equal(t, "myco=route1", TraceStateUpsert("", "myco", "route1"))
+		assert.Equal(t, "myco=route1,foo=bar", TraceStateUpsert("foo=bar", "myco", "route1"))
+	})
+
+	t.Run("update entry moves it to the front", func(t *testing.T) {
+		got := TraceStateUpsert("foo=bar,myco=route1,baz=qux", "myco", "route2")
+		assert.Equal(t, "myco=route2,foo=bar,baz=qux", got)
+	})
+
+	t.Run("invalid key leaves tracestate unchanged", func(t *testing.T) {
+		assert.Equal(t, "foo=bar", TraceStateUpsert("foo=bar", "MYCO", "route1"))
+	})
+
+	t.Run("evict oldest entry over the member limit", func(t *testing.T) {
+		members := make([]string, 32)
+		for i := range members {
+			members[i] = fmt.Sprintf("k%d=v", i)
+		}
+		got := TraceStateUpsert(strings.Join(members, ","), "myco", "route1")
+
+		ts := TraceStateFromW3CString(got)
+		assert.Equal(t, 32, ts.Len())
+		assert.Equal(t, "route1", ts.Get("myco"))
+		assert.Equal(t, "v", ts.Get("k0"))
+		assert.Empty(t, ts.Get("k31"))
+	})
+
+	t.Run("evict oldest entry over the length limit", func(t *testing.T) {
+		long := strings.Repeat("a", 200)
+		got := TraceStateUpsert("k1="+long+",k2="+long, "myco", long)
+
+		assert.LessOrEqual(t, len(got), diagConsts.MaxTracestateLen)
+		ts := TraceStateFromW3CString(got)
+		assert.Equal(t, long, ts.Get("myco"))
+		assert.Equal(t, long, ts.Get("k1"))
+		assert.Empty(t, ts.Get("k2"))
+	})
+}
+
+func TestTraceStateGet(t *testing.T) {
+	v, ok := TraceStateGet("foo=bar,myco=route1", "myco")
+	assert.True(t, ok)
+	assert.Equal(t, "route1", v)
+
+	_, ok = TraceStateGet("foo=bar", "myco")
+	assert.False(t, ok)
+
+	_, ok = TraceStateGet("bad tracestate", "myco")
+	assert.False(t, ok)
+}
+
+func TestActorSpanAttributeValue(t *testing.T) {
+	t.Run("format and parse round-trip", func(t *testing.T) {
+		val := ActorSpanAttributeValue("OrderActor", "order-123")
+		assert.Equal(t, "OrderActor.order-123", val)
+
+		actorType, actorID, ok := ParseActorSpanAttributeValue(val)
+		require.True(t, ok)
+		assert.Equal(t, "OrderActor", actorType)
+		assert.Equal(t, "order-123", actorID)
+	})
+
+	t.Run("actor ID containing the separator", func(t *testing.T) {
+		actorType, actorID, ok := ParseActorSpanAttributeValue(ActorSpanAttributeValue("OrderActor", "order.123"))
+		require.True(t, ok)
+		assert.Equal(t, "OrderActor", actorType)
+		assert.Equal(t, "order.123", actorID)
+	})
+
+	t.Run("hashed actor ID", func(t *testing.T) {
+		val := HashedActorSpanAttributeValue("OrderActor", "user@example.com")
+		assert.NotContains(t, val, "user@example.com")
+		assert.Equal(t, val, HashedActorSpanAttributeValue("OrderActor", "user@example.com"))
+		assert.NotEqual(t, val, HashedActorSpanAttributeValue("OrderActor", "other@example.com"))
+
+		actorType, actorID, ok := ParseActorSpanAttributeValue(val)
+		require.True(t, ok)
+		assert.Equal(t, "OrderActor", actorType)
+		assert.Len(t, actorID, 16)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		for _, val := range []string{"", "OrderActor", ".order-123", "OrderActor."} {
+			_, _, ok := ParseActorSpanAttributeValue(val)
+			assert.False(t, ok, val)
+		}
+	})
+}
+
+func TestBindingSpanAttributes(t *testing.T) {
+	t.Run("input binding", func(t *testing.T) {
+		m := BindingSpanAttributes("kafka-in", "", diagConsts.BindingDirectionInput)
+		assert.Equal(t, map[string]string{
+			"messaging.system":           "bindings",
+			"messaging.destination.name": "kafka-in",
+			"dapr.binding.direction":     "input",
+		}, m)
+	})
+
+	t.Run("output binding", func(t *testing.T) {
+		m := BindingSpanAttributes("blobstore", "create", diagConsts.BindingDirectionOutput)
+		assert.Equal(t, map[string]string{
+			"messaging.system":           "bindings",
+			"messaging.destination.name": "blobstore",
+			"messaging.operation":        "create",
+			"dapr.binding.direction":     "output",
+		}, m)
+	})
+}
+
+func TestAppIDResource(t *testing.T) {
+	var ended sdktrace.ReadOnlySpan
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithResource(AppIDResource("myapp")),
+		sdktrace.WithSpanProcessor(newOtelFakeSpanProcessor(func(s sdktrace.ReadOnlySpan) {
+			ended = s
+		})),
+	)
+	defer func() { _ = tp.Shutdown(t.Context()) }()
+
+	_, span := tp.Tracer("test").Start(t.Context(), "invoke")
+	span.End()
+
+	require.NotNil(t, ended)
+	serviceName, ok := ended.Resource().Set().Value("service.name")
+	require.True(t, ok)
+	assert.Equal(t, "myapp", serviceName.AsString())
+}
+
+func TestAddRetryAttemptEvent(t *testing.T) {
+	var ended sdktrace.ReadOnlySpan
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithSpanProcessor(newOtelFakeSpanProcessor(func(s sdktrace.ReadOnlySpan) {
+			ended = s
+		})),
+	)
+	defer func() { _ = tp.Shutdown(t.Context()) }()
+
+	ctx, span := tp.Tracer("test").Start(t.Context(), "invoke")
+	AddRetryAttemptEvent(ctx, 1, status.Error(codes.Unavailable, "connection refused"))
+	AddRetryAttemptEvent(ctx, 2, errors.New("plain error"))
+	span.End()
+
+	require.NotNil(t, ended)
+	events := ended.Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, diagConsts.RetryAttemptSpanEventName, events[0].Name)
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		attribute.Int(diagConsts.RetryAttemptSpanAttributeKey, 1),
+		attribute.String(diagConsts.RetryAttemptErrorCodeAttributeKey, codes.Unavailable.String()),
+	}, events[0].Attributes)
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		attribute.Int(diagConsts.RetryAttemptSpanAttributeKey, 2),
+		attribute.String(diagConsts.RetryAttemptErrorCodeAttributeKey, codes.Unknown.String()),
+	}, events[1].Attributes)
+
+	t.Run("no span in context", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			AddRetryAttemptEvent(t.Context(), 1, errors.New("plain error"))
+		})
+	})
+}
+
 func TestStartInternalCallbackSpan(t *testing.T) {
 	exp := newOtelFakeExporter()
 
@@ -207,6 +412,94 @@ func TestStartInternalCallbackSpan(t *testing.T) {
 	})
 }
 
+func TestAdjustedSamplingRatioFromTraceState(t *testing.T) {
+	t.Run("threshold is present", func(t *testing.T) {
+		tests := map[string]float64{
+			"ot=th:0":                       1,
+			"ot=th:8":                       0.5,
+			"ot=th:c":                       0.25,
+			"ot=rv:abcdef12345678;th:c":     0.25,
+			"foo=bar,ot=th:8;rv:0123456789": 0.5,
+		}
+		for ts, expected := range tests {
+			ratio, ok := AdjustedSamplingRatioFromTraceState(ts)
+			require.True(t, ok, ts)
+			assert.InDelta(t, expected, ratio, 1e-12, ts)
+		}
+	})
+
+	t.Run("threshold is absent or invalid", func(t *testing.T) {
+		for _, ts := range []string{
+			"",
+			"foo=bar",
+			"ot=rv:abcdef12345678",
+			"ot=th:",
+			"ot=th:C",
+			"ot=th:xyz",
+			"ot=th:123456789abcdef",
+		} {
+			_, ok := AdjustedSamplingRatioFromTraceState(ts)
+			assert.False(t, ok, ts)
+		}
+	})
+}
+
+func TestMethodBasedSampler(t *testing.T) {
+	sampler := NewMethodBasedSampler(0.01, map[string]float64{
+		"/payments.v1.Payments/Charge": 1,
+		"health.v1.Health/Check":       0,
+	})
+	idg := defaultIDGenerator()
+
+	sampled := func(name string) int {
+		count := 0
+		for range 1000 {
+			traceID, _ := idg.NewIDs(t.Context())
+			res := sampler.ShouldSample(sdktrace.SamplingParameters{ParentContext: t.Context(), TraceID: traceID, Name: name})
+			if res.Decision == sdktrace.RecordAndSample {
+				count++
+			}
+		}
+		return count
+	}
+
+	assert.Equal(t, 1000, sampled("payments.v1.Payments/Charge"))
+	assert.Equal(t, 1000, sampled("/payments.v1.Payments/Charge"))
+	assert.Equal(t, 0, sampled("health.v1.Health/Check"))
+	assert.Less(t, sampled("orders.v1.Orders/List"), 100)
+
+	t.Run("decision is deterministic", func(t *testing.T) {
+		traceID, _ := idg.NewIDs(t.Context())
+		params := sdktrace.SamplingParameters{ParentContext: t.Context(), TraceID: traceID, Name: "orders.v1.Orders/List"}
+		first := sampler.ShouldSample(params).Decision
+		for range 10 {
+			assert.Equal(t, first, sampler.ShouldSample(params).Decision)
+		}
+	})
+
+	t.Run("parent decision is followed", func(t *testing.T) {
+		for _, flags := range []trace.TraceFlags{0, trace.FlagsSampled} {
+			traceID, spanID := idg.NewIDs(t.Context())
+			parent := trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID:    traceID,
+				SpanID:     spanID,
+				TraceFlags: flags,
+				Remote:     true,
+			})
+			for _, name := range []string{"payments.v1.Payments/Charge", "health.v1.Health/Check"} {
+				res := sampler.ShouldSample(sdktrace.SamplingParameters{
+					ParentContext: trace.ContextWithRemoteSpanContext(t.Context(), parent),
+					TraceID:       traceID,
+					Name:          name,
+				})
+				assert.Equal(t, flags.IsSampled(), res.Decision == sdktrace.RecordAndSample, name)
+			}
+		}
+	})
+
+	assert.Contains(t, sampler.Description(), "MethodBasedSampler{default:0.01,methods:2}")
+}
+
 func runTraces(t *testing.T, testName string, numTraces int, samplingRate string, hasParentSpanContext bool, parentTraceFlag int) int {
 	d := NewDaprTraceSampler(samplingRate)
 	tracerOptions := []sdktrace.TracerProviderOption{
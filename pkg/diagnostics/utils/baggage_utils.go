@@ -0,0 +1,90 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const upperHex = "0123456789ABCDEF"
+
+// ParseBaggage parses the value of a W3C baggage header into a map of its entries, with
+// percent-encoded values decoded. Properties of the entries, following ";", are discarded.
+// Malformed entries are skipped, and the last of duplicated keys wins.
+// See https://www.w3.org/TR/baggage/#baggage-http-header-format
+func ParseBaggage(value string) map[string]string {
+	entries := make(map[string]string)
+	for _, member := range strings.Split(value, ",") {
+		member, _, _ = strings.Cut(member, ";")
+		key, val, ok := strings.Cut(member, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		val = strings.TrimSpace(val)
+		if decoded, err := url.PathUnescape(val); err == nil {
+			val = decoded
+		}
+		entries[key] = val
+	}
+	return entries
+}
+
+// EncodeBaggage encodes the entries as the value of a W3C baggage header, percent-encoding the
+// characters of the values that are not allowed in a baggage value. Entries are sorted by key.
+// See https://www.w3.org/TR/baggage/#baggage-http-header-format
+func EncodeBaggage(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var sb strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		encodeBaggageValue(&sb, m[k])
+	}
+	return sb.String()
+}
+
+// encodeBaggageValue writes v to sb, percent-encoding the bytes that are not a baggage-octet,
+// as well as "%" itself.
+func encodeBaggageValue(sb *strings.Builder, v string) {
+	for i := range len(v) {
+		c := v[i]
+		if isBaggageOctet(c) && c != '%' {
+			sb.WriteByte(c)
+			continue
+		}
+		sb.WriteByte('%')
+		sb.WriteByte(upperHex[c>>4])
+		sb.WriteByte(upperHex[c&0x0F])
+	}
+}
+
+// isBaggageOctet returns true if c is a printable US-ASCII character other than
+// whitespace, DQUOTE, comma, semicolon, and backslash.
+func isBaggageOctet(c byte) bool {
+	return c > 0x20 && c < 0x7F && c != '"' && c != ',' && c != ';' && c != '\\'
+}
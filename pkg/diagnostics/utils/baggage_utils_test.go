@@ -0,0 +1,95 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	otelBaggage "go.opentelemetry.io/otel/baggage"
+)
+
+func TestParseBaggage(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  map[string]string
+	}{
+		{
+			name:  "multiple entries",
+			value: "userId=alice,serverNode=DF%2028,isProduction=false",
+			want:  map[string]string{"userId": "alice", "serverNode": "DF 28", "isProduction": "false"},
+		},
+		{
+			name:  "entries with properties",
+			value: "key1=value1;property1;property2=p2, key2 = value2 ;ttl=60",
+			want:  map[string]string{"key1": "value1", "key2": "value2"},
+		},
+		{
+			name:  "percent-encoded delimiters",
+			value: "list=a%2Cb%3Bc,quote=%22x%22",
+			want:  map[string]string{"list": "a,b;c", "quote": `"x"`},
+		},
+		{
+			name:  "malformed entries are skipped",
+			value: "key1=value1,novalue,=empty,key2=",
+			want:  map[string]string{"key1": "value1", "key2": ""},
+		},
+		{
+			name:  "invalid percent-encoding is kept",
+			value: "key1=100%",
+			want:  map[string]string{"key1": "100%"},
+		},
+		{
+			name:  "empty",
+			value: "",
+			want:  map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseBaggage(tt.value))
+		})
+	}
+}
+
+func TestEncodeBaggage(t *testing.T) {
+	t.Run("entries are sorted and encoded", func(t *testing.T) {
+		m := map[string]string{
+			"userId":     "alice",
+			"serverNode": "DF 28",
+			"list":       "a,b;c",
+			"ratio":      "100%",
+		}
+		assert.Equal(t, "list=a%2Cb%3Bc,ratio=100%25,serverNode=DF%2028,userId=alice", EncodeBaggage(m))
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		m := map[string]string{
+			"key1": `with "quotes" and \backslash`,
+			"key2": "héllo",
+			"key3": "",
+		}
+		encoded := EncodeBaggage(m)
+		assert.Equal(t, m, ParseBaggage(encoded))
+
+		// The encoded value is a valid baggage header.
+		_, err := otelBaggage.Parse(encoded)
+		assert.NoError(t, err)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		assert.Empty(t, EncodeBaggage(nil))
+	})
+}
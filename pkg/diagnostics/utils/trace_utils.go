@@ -15,12 +15,15 @@ package utils
 
 import (
 	"context"
+	"encoding/binary"
 	"net/http"
 	"strconv"
+	"strings"
 
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
 
+	diagConsts "github.com/dapr/dapr/pkg/diagnostics/consts"
 	"github.com/dapr/kit/logger"
 )
 
@@ -94,6 +97,20 @@ func IsTracingEnabled(rate string) bool {
 	return GetTraceSamplingRate(rate) != 0
 }
 
+// ShouldSample returns true if the trace with the given ID should be sampled with the sampling ratio.
+// The decision is derived from the trace ID with the same algorithm as the OpenTelemetry TraceIDRatioBased
+// sampler, so it is consistent across hops for the same trace.
+func ShouldSample(traceID trace.TraceID, ratio float64) bool {
+	if ratio >= 1 {
+		return true
+	}
+	if ratio <= 0 {
+		return false
+	}
+	upperBound := uint64(ratio * (1 << 63))
+	return binary.BigEndian.Uint64(traceID[8:16])>>1 < upperBound
+}
+
 // SpanFromContext returns the Span stored in a context, or nil or trace.noopSpan{} if there isn't one.
 func SpanFromContext(ctx context.Context) trace.Span {
 	val := ctx.Value(spanContextKey)
@@ -159,3 +176,92 @@ func SpanContextFromBinary(b []byte) (sc trace.SpanContext, ok bool) {
 	sc = trace.NewSpanContext(scConfig)
 	return sc, true
 }
+
+// SpanContextFromB3 returns the SpanContext represented by B3 headers, using getHeader to read them.
+// The single "b3" header takes precedence over the multi-header "X-B3-*" form.
+//
+// If the headers are missing or malformed, SpanContextFromB3 returns with ok==false.
+func SpanContextFromB3(getHeader func(string) string) (sc trace.SpanContext, ok bool) {
+	if h := getHeader(diagConsts.B3SingleHeader); h != "" {
+		return SpanContextFromB3Single(h)
+	}
+
+	sampled := getHeader(diagConsts.B3SampledHeader)
+	if getHeader(diagConsts.B3FlagsHeader) == "1" {
+		sampled = "d"
+	}
+	return spanContextFromB3Fields(getHeader(diagConsts.B3TraceIDHeader), getHeader(diagConsts.B3SpanIDHeader), sampled)
+}
+
+// SpanContextFromB3Single returns the SpanContext represented by the value of a single "b3" header,
+// in the {TraceId}-{SpanId}-{SamplingState}-{ParentSpanId} form, where the last two fields are optional.
+//
+// If h is malformed, or only carries a sampling state, SpanContextFromB3Single returns with ok==false.
+func SpanContextFromB3Single(h string) (sc trace.SpanContext, ok bool) {
+	parts := strings.Split(h, "-")
+	if len(parts) < 2 || len(parts) > 4 {
+		return trace.SpanContext{}, false
+	}
+	var sampled string
+	if len(parts) > 2 {
+		sampled = parts[2]
+	}
+	return spanContextFromB3Fields(parts[0], parts[1], sampled)
+}
+
+func spanContextFromB3Fields(traceID, spanID, sampled string) (trace.SpanContext, bool) {
+	// 64-bit trace IDs are left-padded to 128 bits.
+	if len(traceID) == 16 {
+		traceID = strings.Repeat("0", 16) + traceID
+	}
+	if len(traceID) != 32 || len(spanID) != 16 {
+		return trace.SpanContext{}, false
+	}
+
+	var (
+		scConfig trace.SpanContextConfig
+		err      error
+	)
+	scConfig.TraceID, err = trace.TraceIDFromHex(traceID)
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+	scConfig.SpanID, err = trace.SpanIDFromHex(spanID)
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+
+	switch strings.ToLower(sampled) {
+	case "1", "d", "true":
+		scConfig.TraceFlags = trace.FlagsSampled
+	case "", "0", "false":
+	default:
+		return trace.SpanContext{}, false
+	}
+
+	return trace.NewSpanContext(scConfig), true
+}
+
+// B3FromSpanContext writes the B3 representation of sc using setHeader, either as
+// a single "b3" header or as multiple "X-B3-*" headers.
+//
+// If sc is the zero value, B3FromSpanContext does nothing.
+func B3FromSpanContext(sc trace.SpanContext, setHeader func(string, string), singleHeader bool) {
+	if sc.Equal(emptySpanContext) {
+		return
+	}
+
+	sampled := "0"
+	if sc.IsSampled() {
+		sampled = "1"
+	}
+
+	if singleHeader {
+		setHeader(diagConsts.B3SingleHeader, sc.TraceID().String()+"-"+sc.SpanID().String()+"-"+sampled)
+		return
+	}
+
+	setHeader(diagConsts.B3TraceIDHeader, sc.TraceID().String())
+	setHeader(diagConsts.B3SpanIDHeader, sc.SpanID().String())
+	setHeader(diagConsts.B3SampledHeader, sampled)
+}
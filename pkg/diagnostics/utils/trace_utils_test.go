@@ -15,6 +15,7 @@ package utils
 
 import (
 	"context"
+	"encoding/binary"
 	"net/http"
 	"reflect"
 	"testing"
@@ -101,3 +102,139 @@ func (e *otelFakeExporter) ExportSpans(ctx context.Context, spans []sdktrace.Rea
 func (e *otelFakeExporter) Shutdown(ctx context.Context) error {
 	return nil
 }
+
+func TestSpanContextFromB3(t *testing.T) {
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+
+	headerGetter := func(headers map[string]string) func(string) string {
+		return func(key string) string {
+			return headers[key]
+		}
+	}
+
+	t.Run("single header", func(t *testing.T) {
+		sc, ok := SpanContextFromB3(headerGetter(map[string]string{
+			"b3": traceID + "-" + spanID + "-1",
+		}))
+		assert.True(t, ok)
+		assert.Equal(t, traceID, sc.TraceID().String())
+		assert.Equal(t, spanID, sc.SpanID().String())
+		assert.True(t, sc.IsSampled())
+	})
+
+	t.Run("single header with 64-bit trace ID and parent", func(t *testing.T) {
+		sc, ok := SpanContextFromB3Single("a3ce929d0e0e4736-" + spanID + "-0-05e3ac9a4f6e3b90")
+		assert.True(t, ok)
+		assert.Equal(t, "0000000000000000a3ce929d0e0e4736", sc.TraceID().String())
+		assert.False(t, sc.IsSampled())
+	})
+
+	t.Run("multi header", func(t *testing.T) {
+		sc, ok := SpanContextFromB3(headerGetter(map[string]string{
+			"x-b3-traceid": traceID,
+			"x-b3-spanid":  spanID,
+			"x-b3-sampled": "1",
+		}))
+		assert.True(t, ok)
+		assert.Equal(t, traceID, sc.TraceID().String())
+		assert.Equal(t, spanID, sc.SpanID().String())
+		assert.True(t, sc.IsSampled())
+	})
+
+	t.Run("multi header with debug flag", func(t *testing.T) {
+		sc, ok := SpanContextFromB3(headerGetter(map[string]string{
+			"x-b3-traceid": traceID,
+			"x-b3-spanid":  spanID,
+			"x-b3-flags":   "1",
+		}))
+		assert.True(t, ok)
+		assert.True(t, sc.IsSampled())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, h := range []string{"0", "1", traceID, traceID + "-xyz-1", traceID + "-" + spanID + "-2"} {
+			_, ok := SpanContextFromB3Single(h)
+			assert.False(t, ok, h)
+		}
+		_, ok := SpanContextFromB3(headerGetter(nil))
+		assert.False(t, ok)
+	})
+}
+
+func TestB3FromSpanContext(t *testing.T) {
+	sc, ok := SpanContextFromB3Single("4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1")
+	assert.True(t, ok)
+
+	t.Run("single header", func(t *testing.T) {
+		headers := map[string]string{}
+		B3FromSpanContext(sc, func(k, v string) { headers[k] = v }, true)
+		assert.Equal(t, map[string]string{
+			"b3": "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1",
+		}, headers)
+	})
+
+	t.Run("multi header", func(t *testing.T) {
+		headers := map[string]string{}
+		B3FromSpanContext(sc, func(k, v string) { headers[k] = v }, false)
+		assert.Equal(t, map[string]string{
+			"x-b3-traceid": "4bf92f3577b34da6a3ce929d0e0e4736",
+			"x-b3-spanid":  "00f067aa0ba902b7",
+			"x-b3-sampled": "1",
+		}, headers)
+	})
+
+	t.Run("empty span context", func(t *testing.T) {
+		headers := map[string]string{}
+		B3FromSpanContext(trace.SpanContext{}, func(k, v string) { headers[k] = v }, true)
+		assert.Empty(t, headers)
+	})
+}
+
+func TestShouldSample(t *testing.T) {
+	traceID := func(i uint64) trace.TraceID {
+		var id trace.TraceID
+		// Spread the IDs over the whole range, as random IDs would be.
+		binary.BigEndian.PutUint64(id[8:], i*0x9e3779b97f4a7c15)
+		return id
+	}
+
+	t.Run("decision is deterministic", func(t *testing.T) {
+		for i := range uint64(100) {
+			id := traceID(i)
+			assert.Equal(t, ShouldSample(id, 0.5), ShouldSample(id, 0.5))
+		}
+	})
+
+	t.Run("always and never", func(t *testing.T) {
+		id := traceID(42)
+		assert.True(t, ShouldSample(id, 1))
+		assert.True(t, ShouldSample(id, 2))
+		assert.False(t, ShouldSample(id, 0))
+		assert.False(t, ShouldSample(id, -1))
+	})
+
+	t.Run("consistent with the OpenTelemetry sampler", func(t *testing.T) {
+		sampler := sdktrace.TraceIDRatioBased(0.25)
+		for i := range uint64(1000) {
+			id := traceID(i)
+			res := sampler.ShouldSample(sdktrace.SamplingParameters{TraceID: id})
+			assert.Equal(t, res.Decision == sdktrace.RecordAndSample, ShouldSample(id, 0.25))
+		}
+	})
+
+	t.Run("distribution", func(t *testing.T) {
+		const n = 100_000
+		for _, ratio := range []float64{0.01, 0.1, 0.5} {
+			sampled := 0
+			for i := range uint64(n) {
+				if ShouldSample(traceID(i), ratio) {
+					sampled++
+				}
+			}
+			assert.InDelta(t, ratio, float64(sampled)/n, 0.01, "ratio %v", ratio)
+		}
+	})
+}
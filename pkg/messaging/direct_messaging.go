@@ -77,6 +77,10 @@ type directMessaging struct {
 	compStore           *compstore.ComponentStore
 	resolverCache       *ttlcache.Cache[nr.AddressList]
 	closed              atomic.Bool
+	// maxInvocationChainLength is the maximum length of the invocation chain; 0 disables it.
+	maxInvocationChainLength int
+	// invocationLoopByMethod detects loops on the app and method, instead of the app only.
+	invocationLoopByMethod bool
 }
 
 type remoteApp struct {
@@ -101,6 +105,13 @@ type NewDirectMessagingOpts struct {
 	Proxy              Proxy
 	ReadBufferSize     int
 	Resiliency         resiliency.Provider
+	// MaxInvocationChainLength is the maximum number of entries kept in the invocation chain of the
+	// requests, used to detect invocation loops. If 0, the invocation chain and loop detection are disabled.
+	MaxInvocationChainLength int
+	// InvocationLoopByMethod makes the invocation of an app a loop only if the same method of the app
+	// already appears in the invocation chain, rather than the app with any method. This allows callbacks to
+	// an app in the chain on another method. It must be enabled on all the sidecars of the apps in the chain.
+	InvocationLoopByMethod bool
 }
 
 // NewDirectMessaging returns a new direct messaging api.
@@ -130,6 +141,9 @@ func NewDirectMessaging(opts NewDirectMessagingOpts) invokev1.DirectMessaging {
 		hostFwdAddr:         hFwdAddr,
 		hostName:            hName,
 		compStore:           opts.CompStore,
+
+		maxInvocationChainLength: opts.MaxInvocationChainLength,
+		invocationLoopByMethod:   opts.InvocationLoopByMethod,
 	}
 
 	// Set resolverMulti if the resolver implements the ResolverMulti interface
@@ -186,6 +200,10 @@ func (d *directMessaging) Invoke(ctx context.Context, targetAppID string, req *i
 		return d.invokeLocal(ctx, req)
 	}
 
+	if err = d.addInvocationChainToMetadata(req, app.id); err != nil {
+		return nil, err
+	}
+
 	return d.invokeWithRetry(ctx, retry.DefaultLinearRetryCount, retry.DefaultLinearBackoffInterval, app, d.invokeRemote, req)
 }
 
@@ -258,6 +276,7 @@ func (d *directMessaging) invokeWithRetry(
 				if app.cacheKey != "" && d.resolverCache != nil {
 					d.resolverCache.Delete(app.cacheKey)
 				}
+				diag.AddRetryAttemptEvent(ctx, int(attempt), rErr)
 				return rResp, fmt.Errorf("failed to invoke target %s after %d retries. Error: %w", app.id, attempt-1, rErr)
 			}
 
@@ -583,6 +602,38 @@ func (d *directMessaging) addCallerAndCalleeAppIDHeaderToMetadata(callerNamespac
 	}
 }
 
+// addInvocationChainToMetadata adds the target app to the invocation chain of the request, or returns a
+// FailedPrecondition error if it already appears in it, as the request is part of an invocation loop. The
+// chain received from the app is kept only if it was forwarded from a request being handled by the app, as
+// chains set by the app or its clients are not trusted; otherwise, a new chain starts with this app.
+func (d *directMessaging) addInvocationChainToMetadata(req *invokev1.InvokeMethodRequest, targetAppID string) error {
+	md := req.Metadata()
+	if d.maxInvocationChainLength <= 0 {
+		invokev1.SetInvocationChain(md, nil, 0)
+		return nil
+	}
+
+	chain := invokev1.TrustedInvocationChain(md)
+	if len(chain) == 0 {
+		chain = []string{d.appID}
+	}
+	invokev1.SetInvocationChain(md, chain, d.maxInvocationChainLength)
+
+	entry := targetAppID
+	if d.invocationLoopByMethod {
+		method := req.Message().GetMethod()
+		if invokev1.DetectInvocationMethodLoop(md, targetAppID, method) {
+			return status.Errorf(codes.FailedPrecondition, "invocation loop detected: method %s of app %s already appears in the invocation chain %v", method, targetAppID, invokev1.InvocationChain(md))
+		}
+		entry = invokev1.InvocationChainEntry(targetAppID, method)
+	} else if invokev1.DetectInvocationLoop(md, targetAppID) {
+		return status.Errorf(codes.FailedPrecondition, "invocation loop detected: app %s already appears in the invocation chain %v", targetAppID, invokev1.InvocationChain(md))
+	}
+
+	invokev1.SetInvocationChain(md, append(invokev1.InvocationChain(md), entry), d.maxInvocationChainLength)
+	return nil
+}
+
 func (d *directMessaging) addForwardedHeadersToMetadata(req *invokev1.InvokeMethodRequest) {
 	metadata := req.Metadata()
 
@@ -27,7 +27,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/anypb"
 
 	"github.com/dapr/dapr/pkg/channel"
@@ -73,6 +75,88 @@ func TestCallerAndCalleeHeaders(t *testing.T) {
 	})
 }
 
+func TestInvocationChainHeader(t *testing.T) {
+	newRequest := func(t *testing.T, chain string) *invokev1.InvokeMethodRequest {
+		req := invokev1.NewInvokeMethodRequest("orders").
+			WithMetadata(map[string][]string{invokev1.InvocationChainHeader: {chain}})
+		t.Cleanup(func() { req.Close() })
+		return req
+	}
+
+	t.Run("3-hop loop is rejected", func(t *testing.T) {
+		// app-a invokes app-b, whose app invokes app-a again while handling the request.
+		req := invokev1.NewInvokeMethodRequest("orders").WithMetadata(map[string][]string{})
+		defer req.Close()
+		dmA := &directMessaging{appID: "app-a", maxInvocationChainLength: invokev1.DefaultMaxInvocationChainLength}
+		require.NoError(t, dmA.addInvocationChainToMetadata(req, "app-b"))
+		assert.Equal(t, []string{"app-a", "app-b"}, invokev1.InvocationChain(req.Metadata()))
+
+		// The sidecar of app-b tracks the chain while app-b handles the request, and app-b forwards it.
+		defer invokev1.TrackInvocationChain(req.Metadata())()
+		forwarded := newRequest(t, req.Metadata()[invokev1.InvocationChainHeader].GetValues()[0])
+
+		dmB := &directMessaging{appID: "app-b", maxInvocationChainLength: invokev1.DefaultMaxInvocationChainLength}
+		err := dmB.addInvocationChainToMetadata(forwarded, "app-a")
+		require.Error(t, err)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("app is added to the forwarded chain", func(t *testing.T) {
+		req := newRequest(t, "app-a,app-b")
+		defer invokev1.TrackInvocationChain(req.Metadata())()
+
+		dm := &directMessaging{appID: "app-b", maxInvocationChainLength: invokev1.DefaultMaxInvocationChainLength}
+		require.NoError(t, dm.addInvocationChainToMetadata(req, "app-c"))
+		assert.Equal(t, []string{"app-a", "app-b", "app-c"}, invokev1.InvocationChain(req.Metadata()))
+	})
+
+	t.Run("other method of an app in the chain is a loop", func(t *testing.T) {
+		req := newRequest(t, "app-a,app-b")
+		defer invokev1.TrackInvocationChain(req.Metadata())()
+
+		dm := &directMessaging{appID: "app-b", maxInvocationChainLength: invokev1.DefaultMaxInvocationChainLength}
+		err := dm.addInvocationChainToMetadata(req, "app-a")
+		require.Error(t, err)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("loop by method", func(t *testing.T) {
+		dm := &directMessaging{
+			appID:                    "app-b",
+			maxInvocationChainLength: invokev1.DefaultMaxInvocationChainLength,
+			invocationLoopByMethod:   true,
+		}
+
+		req := newRequest(t, "app-a,"+invokev1.InvocationChainEntry("app-b", "pay"))
+		defer invokev1.TrackInvocationChain(req.Metadata())()
+		require.NoError(t, dm.addInvocationChainToMetadata(req, "app-a"))
+		assert.Equal(t, []string{"app-a", "app-b/pay", "app-a/orders"}, invokev1.InvocationChain(req.Metadata()))
+
+		req = newRequest(t, "app-a/orders,app-b/pay")
+		defer invokev1.TrackInvocationChain(req.Metadata())()
+		err := dm.addInvocationChainToMetadata(req, "app-a")
+		require.Error(t, err)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("chain not forwarded from a request is dropped", func(t *testing.T) {
+		req := newRequest(t, "app-c,app-a")
+
+		dm := &directMessaging{appID: "app-b", maxInvocationChainLength: invokev1.DefaultMaxInvocationChainLength}
+		require.NoError(t, dm.addInvocationChainToMetadata(req, "app-c"))
+		assert.Equal(t, []string{"app-b", "app-c"}, invokev1.InvocationChain(req.Metadata()))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		req := newRequest(t, "app-a,app-b")
+		defer invokev1.TrackInvocationChain(req.Metadata())()
+
+		dm := &directMessaging{appID: "app-b"}
+		require.NoError(t, dm.addInvocationChainToMetadata(req, "app-a"))
+		assert.Empty(t, invokev1.InvocationChain(req.Metadata()))
+	})
+}
+
 func TestInvokeLocalCallerAndCalleeHeaders(t *testing.T) {
 	const appID = "myapp"
 	const namespace = "myns"
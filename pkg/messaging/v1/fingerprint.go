@@ -0,0 +1,67 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+
+	diagConsts "github.com/dapr/dapr/pkg/diagnostics/consts"
+)
+
+// fingerprintExcludedHeaders are the volatile headers that differ between retries of the same request,
+// such as the trace context and timestamps, and are excluded from RequestFingerprint.
+var fingerprintExcludedHeaders = map[string]struct{}{
+	diagConsts.TraceparentHeader:    {},
+	diagConsts.TracestateHeader:     {},
+	diagConsts.GRPCTraceContextKey:  {},
+	diagConsts.BaggageHeader:        {},
+	diagConsts.B3SingleHeader:       {},
+	diagConsts.B3TraceIDHeader:      {},
+	diagConsts.B3SpanIDHeader:       {},
+	diagConsts.B3ParentSpanIDHeader: {},
+	diagConsts.B3SampledHeader:      {},
+	diagConsts.B3FlagsHeader:        {},
+	"date":                          {},
+	"grpc-timeout":                  {},
+	DaprAPITokenHeader:              {},
+}
+
+// RequestFingerprint returns a stable hex-encoded SHA-256 hash of the method, metadata, and body of a
+// request, to detect duplicate retries. Metadata keys are canonicalized and sorted, and the volatile
+// headers in fingerprintExcludedHeaders, such as the trace context, are excluded.
+func RequestFingerprint(method string, md DaprInternalMetadata, body []byte) string {
+	keys, values := sortedCanonicalMetadata(md, fingerprintExcludedHeaders)
+
+	h := sha256.New()
+	// Each field is prefixed with its length, so that different requests cannot produce the same input.
+	writeFingerprintField(h, []byte(method))
+	for _, key := range keys {
+		writeFingerprintField(h, []byte(key))
+		vals := values[key]
+		binary.Write(h, binary.BigEndian, uint64(len(vals))) //nolint:errcheck
+		for _, v := range vals {
+			writeFingerprintField(h, []byte(v))
+		}
+	}
+	writeFingerprintField(h, body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeFingerprintField(h hash.Hash, b []byte) {
+	binary.Write(h, binary.BigEndian, uint64(len(b))) //nolint:errcheck
+	h.Write(b)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	diagConsts "github.com/dapr/dapr/pkg/diagnostics/consts"
+)
+
+func TestRequestFingerprint(t *testing.T) {
+	body := []byte(`{"orderId":1}`)
+	newMD := func(traceparent string) DaprInternalMetadata {
+		return DaprInternalMetadata{
+			ContentTypeHeader:            SingleValue(JSONContentType),
+			"x-custom":                   NewListStringValue("a", "b"),
+			diagConsts.TraceparentHeader: SingleValue(traceparent),
+		}
+	}
+
+	fp := RequestFingerprint("orders", newMD("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"), body)
+	assert.Len(t, fp, 64)
+
+	t.Run("only traceparent differs", func(t *testing.T) {
+		assert.Equal(t, fp, RequestFingerprint("orders", newMD("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"), body))
+	})
+
+	t.Run("other volatile headers are excluded", func(t *testing.T) {
+		md := newMD("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		md["Date"] = SingleValue("Wed, 21 Oct 2026 07:28:00 GMT")
+		md[diagConsts.TracestateHeader] = SingleValue("congo=t61rcWkgMzE")
+		assert.Equal(t, fp, RequestFingerprint("orders", md, body))
+	})
+
+	t.Run("keys are canonicalized", func(t *testing.T) {
+		md := newMD("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		md["X-Custom"] = md["x-custom"]
+		delete(md, "x-custom")
+		assert.Equal(t, fp, RequestFingerprint("orders", md, body))
+	})
+
+	t.Run("method, metadata, and body are hashed", func(t *testing.T) {
+		md := newMD("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		assert.NotEqual(t, fp, RequestFingerprint("payments", md, body))
+		assert.NotEqual(t, fp, RequestFingerprint("orders", md, []byte(`{"orderId":2}`)))
+
+		md["x-custom"] = NewListStringValue("b", "a")
+		assert.NotEqual(t, fp, RequestFingerprint("orders", md, body))
+	})
+
+	t.Run("fields are delimited", func(t *testing.T) {
+		assert.NotEqual(t,
+			RequestFingerprint("a", DaprInternalMetadata{"b": SingleValue("c")}, nil),
+			RequestFingerprint("ab", DaprInternalMetadata{}, []byte("c")),
+		)
+	})
+}
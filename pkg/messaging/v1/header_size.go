@@ -0,0 +1,104 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	diagConsts "github.com/dapr/dapr/pkg/diagnostics/consts"
+)
+
+// headerLineOverhead is the size of the ": " separator and of the CRLF terminating each header line.
+const headerLineOverhead = 4
+
+// maxResponseHeaderSize is the maximum size of the headers converted by InternalMetadataToHTTPHeader.
+// Zero disables the limit.
+var maxResponseHeaderSize int
+
+// SetMaxResponseHeaderSize sets the maximum size, as computed by ResponseHeaderSize, of the headers
+// converted by InternalMetadataToHTTPHeader, such as the headers of responses to HTTP clients. Some
+// proxies reject responses whose headers exceed 8KB. When the headers exceed the limit, the largest
+// non-essential headers are dropped until they fit; the trace context, content-type, and content-length
+// headers are always kept. Zero, the default, disables the limit. This is not safe for concurrent use
+// and should be called during initialization, before any metadata is converted.
+func SetMaxResponseHeaderSize(size int) {
+	maxResponseHeaderSize = size
+}
+
+// ResponseHeaderSize returns the size of the metadata as HTTP/1.1 header lines: the length of the key
+// and of the value, plus the separator and line terminator, for each value.
+func ResponseHeaderSize(md DaprInternalMetadata) int {
+	var size int
+	for k, v := range md {
+		size += headerSize(k, v.GetValues())
+	}
+	return size
+}
+
+func headerSize(key string, values []string) int {
+	var size int
+	for _, v := range values {
+		size += len(key) + len(v) + headerLineOverhead
+	}
+	return size
+}
+
+// isEssentialHeader returns true if the header, in lowercase, is never dropped to fit the maximum size.
+func isEssentialHeader(key string) bool {
+	switch key {
+	case ContentTypeHeader, ContentLengthHeader,
+		diagConsts.TraceparentHeader, diagConsts.TracestateHeader, diagConsts.GRPCTraceContextKey, diagConsts.BaggageHeader:
+		return true
+	}
+	return isB3Header(key)
+}
+
+// headersToTrim returns the keys of the non-essential headers of md to drop for its size to fit limit,
+// largest first so that as few headers as possible are dropped, or nil if it fits.
+func headersToTrim(md DaprInternalMetadata, limit int) map[string]struct{} {
+	size := ResponseHeaderSize(md)
+	if size <= limit {
+		return nil
+	}
+
+	type header struct {
+		key  string
+		size int
+	}
+	headers := make([]header, 0, len(md))
+	for k, v := range md {
+		if isEssentialHeader(CanonicalMetadataKey(k)) {
+			continue
+		}
+		headers = append(headers, header{key: k, size: headerSize(k, v.GetValues())})
+	}
+	slices.SortFunc(headers, func(a, b header) int {
+		if c := cmp.Compare(b.size, a.size); c != 0 {
+			return c
+		}
+		return strings.Compare(a.key, b.key)
+	})
+
+	trimmed := make(map[string]struct{})
+	for _, h := range headers {
+		if size <= limit {
+			break
+		}
+		trimmed[h.key] = struct{}{}
+		size -= h.size
+	}
+	return trimmed
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+
+	diag "github.com/dapr/dapr/pkg/diagnostics"
+	diagConsts "github.com/dapr/dapr/pkg/diagnostics/consts"
+)
+
+func TestResponseHeaderSize(t *testing.T) {
+	assert.Equal(t, 0, ResponseHeaderSize(nil))
+	// "x-a: 1\r\n" + "x-b: 22\r\n" + "x-b: 333\r\n"
+	assert.Equal(t, 8+9+10, ResponseHeaderSize(DaprInternalMetadata{
+		"x-a": SingleValue("1"),
+		"x-b": NewListStringValue("22", "333"),
+	}))
+}
+
+func TestMaxResponseHeaderSize(t *testing.T) {
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	orig := *diag.DefaultGRPCMonitoring
+	t.Cleanup(func() {
+		*diag.DefaultGRPCMonitoring = orig
+	})
+	meter := view.NewMeter()
+	meter.Start()
+	t.Cleanup(meter.Stop)
+	require.NoError(t, diag.DefaultGRPCMonitoring.Init(meter, "test", view.Distribution(1, 10, 100)))
+
+	SetMaxResponseHeaderSize(8 << 10)
+	t.Cleanup(func() {
+		SetMaxResponseHeaderSize(0)
+	})
+
+	convert := func(md DaprInternalMetadata) map[string]string {
+		headers := map[string]string{}
+		InternalMetadataToHTTPHeader(t.Context(), md, func(k, v string) {
+			headers[k] = v
+		})
+		return headers
+	}
+	trimmedCount := func() int64 {
+		rows, err := meter.RetrieveData("grpc.io/headers/trimmed_count")
+		require.NoError(t, err)
+		if len(rows) == 0 {
+			return 0
+		}
+		return rows[0].Data.(*view.CountData).Value
+	}
+
+	t.Run("oversized headers are trimmed", func(t *testing.T) {
+		headers := convert(DaprInternalMetadata{
+			diagConsts.TraceparentHeader: SingleValue(traceparent),
+			diagConsts.TracestateHeader:  SingleValue("congo=t61rcWkgMzE"),
+			ContentTypeHeader:            SingleValue(JSONContentType),
+			"x-large-cookie":             SingleValue(strings.Repeat("a", 6<<10)),
+			"x-large-token":              SingleValue(strings.Repeat("b", 4<<10)),
+			"x-small":                    SingleValue("small"),
+		})
+
+		// Dropping the largest header is enough for the others to fit.
+		assert.NotContains(t, headers, "x-large-cookie")
+		assert.Len(t, headers["x-large-token"], 4<<10)
+		assert.Equal(t, "small", headers["x-small"])
+		assert.Equal(t, traceparent, headers[diagConsts.TraceparentHeader])
+		assert.Equal(t, "congo=t61rcWkgMzE", headers[diagConsts.TracestateHeader])
+		assert.Equal(t, int64(1), trimmedCount())
+	})
+
+	t.Run("headers within the limit are kept", func(t *testing.T) {
+		headers := convert(DaprInternalMetadata{
+			diagConsts.TraceparentHeader: SingleValue(traceparent),
+			"x-small":                    SingleValue("small"),
+		})
+
+		assert.Equal(t, "small", headers["x-small"])
+		assert.Equal(t, traceparent, headers[diagConsts.TraceparentHeader])
+		assert.Equal(t, int64(1), trimmedCount())
+	})
+}
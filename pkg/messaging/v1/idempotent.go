@@ -0,0 +1,89 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"net/http"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	runtimev1pb "github.com/dapr/dapr/pkg/proto/runtime/v1"
+)
+
+var (
+	idempotentMethodsLock sync.RWMutex
+	// idempotentMethods classifies known Dapr API methods by whether they can be retried safely.
+	// Methods that are not listed are considered not idempotent.
+	idempotentMethods = map[string]bool{
+		runtimev1pb.Dapr_GetState_FullMethodName:           true,
+		runtimev1pb.Dapr_GetBulkState_FullMethodName:       true,
+		runtimev1pb.Dapr_SaveState_FullMethodName:          true,
+		runtimev1pb.Dapr_QueryStateAlpha1_FullMethodName:   true,
+		runtimev1pb.Dapr_DeleteState_FullMethodName:        true,
+		runtimev1pb.Dapr_DeleteBulkState_FullMethodName:    true,
+		runtimev1pb.Dapr_GetSecret_FullMethodName:          true,
+		runtimev1pb.Dapr_GetBulkSecret_FullMethodName:      true,
+		runtimev1pb.Dapr_GetActorState_FullMethodName:      true,
+		runtimev1pb.Dapr_GetActorReminder_FullMethodName:   true,
+		runtimev1pb.Dapr_ListActorReminders_FullMethodName: true,
+		runtimev1pb.Dapr_GetConfiguration_FullMethodName:   true,
+		runtimev1pb.Dapr_GetMetadata_FullMethodName:        true,
+		runtimev1pb.Dapr_GetJob_FullMethodName:             true,
+		runtimev1pb.Dapr_DeleteJob_FullMethodName:          true,
+		runtimev1pb.Dapr_ListJobs_FullMethodName:           true,
+
+		runtimev1pb.Dapr_ExecuteStateTransaction_FullMethodName:      false,
+		runtimev1pb.Dapr_ExecuteActorStateTransaction_FullMethodName: false,
+		runtimev1pb.Dapr_PublishEvent_FullMethodName:                 false,
+		runtimev1pb.Dapr_BulkPublishEvent_FullMethodName:             false,
+		runtimev1pb.Dapr_InvokeService_FullMethodName:                false,
+		runtimev1pb.Dapr_InvokeBinding_FullMethodName:                false,
+		runtimev1pb.Dapr_InvokeActor_FullMethodName:                  false,
+	}
+)
+
+// IsIdempotentMethod returns true if the gRPC method, in the "/package.Service/Method" form,
+// is known to be idempotent and can be retried safely.
+func IsIdempotentMethod(fullMethod string) bool {
+	idempotentMethodsLock.RLock()
+	defer idempotentMethodsLock.RUnlock()
+	return idempotentMethods[fullMethod]
+}
+
+// RegisterIdempotentMethod sets whether the gRPC method, in the "/package.Service/Method" form,
+// is idempotent. It can be used to classify custom services, or to override the defaults.
+func RegisterIdempotentMethod(fullMethod string, idempotent bool) {
+	idempotentMethodsLock.Lock()
+	defer idempotentMethodsLock.Unlock()
+	idempotentMethods[fullMethod] = idempotent
+}
+
+// IsRetriable returns true if a call to the gRPC method that failed with err can be retried: the
+// method is idempotent, per IsIdempotentMethod, and the error is transient, with the Unavailable,
+// DeadlineExceeded, or ResourceExhausted code. A payload too large, per ErrorPayloadTooLarge, is
+// ResourceExhausted too but is not transient, so it is never retriable.
+func IsRetriable(fullMethod string, err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return IsIdempotentMethod(fullMethod)
+	case codes.ResourceExhausted:
+		if HTTPStatusFromError(err) == http.StatusRequestEntityTooLarge {
+			return false
+		}
+		return IsIdempotentMethod(fullMethod)
+	}
+	return false
+}
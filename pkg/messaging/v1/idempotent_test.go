@@ -0,0 +1,83 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	runtimev1pb "github.com/dapr/dapr/pkg/proto/runtime/v1"
+)
+
+func TestIsIdempotentMethod(t *testing.T) {
+	t.Run("known methods", func(t *testing.T) {
+		assert.True(t, IsIdempotentMethod(runtimev1pb.Dapr_GetState_FullMethodName))
+		assert.True(t, IsIdempotentMethod(runtimev1pb.Dapr_DeleteState_FullMethodName))
+		assert.False(t, IsIdempotentMethod(runtimev1pb.Dapr_ExecuteStateTransaction_FullMethodName))
+		assert.False(t, IsIdempotentMethod(runtimev1pb.Dapr_PublishEvent_FullMethodName))
+	})
+
+	t.Run("unknown method", func(t *testing.T) {
+		assert.False(t, IsIdempotentMethod("/myco.Custom/Get"))
+	})
+
+	t.Run("register custom method", func(t *testing.T) {
+		t.Cleanup(func() {
+			idempotentMethodsLock.Lock()
+			delete(idempotentMethods, "/myco.Custom/Get")
+			idempotentMethodsLock.Unlock()
+		})
+
+		RegisterIdempotentMethod("/myco.Custom/Get", true)
+		assert.True(t, IsIdempotentMethod("/myco.Custom/Get"))
+	})
+
+	t.Run("override default", func(t *testing.T) {
+		t.Cleanup(func() {
+			RegisterIdempotentMethod(runtimev1pb.Dapr_SaveState_FullMethodName, true)
+		})
+
+		RegisterIdempotentMethod(runtimev1pb.Dapr_SaveState_FullMethodName, false)
+		assert.False(t, IsIdempotentMethod(runtimev1pb.Dapr_SaveState_FullMethodName))
+	})
+}
+
+func TestIsRetriable(t *testing.T) {
+	t.Run("idempotent method and retriable error", func(t *testing.T) {
+		for _, code := range []codes.Code{codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted} {
+			assert.True(t, IsRetriable(runtimev1pb.Dapr_GetState_FullMethodName, status.Error(code, "failed")), code)
+		}
+	})
+
+	t.Run("idempotent method and non-retriable error", func(t *testing.T) {
+		for _, err := range []error{
+			status.Error(codes.InvalidArgument, "failed"),
+			status.Error(codes.NotFound, "failed"),
+			status.Error(codes.Internal, "failed"),
+			ErrorPayloadTooLarge(8<<20, 4<<20),
+			nil,
+		} {
+			assert.False(t, IsRetriable(runtimev1pb.Dapr_GetState_FullMethodName, err), err)
+		}
+	})
+
+	t.Run("non-idempotent method", func(t *testing.T) {
+		err := status.Error(codes.Unavailable, "failed")
+		assert.False(t, IsRetriable(runtimev1pb.Dapr_PublishEvent_FullMethodName, err))
+		assert.False(t, IsRetriable("/myapp.v1.OrderService/CreateOrder", err))
+	})
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	internalv1pb "github.com/dapr/dapr/pkg/proto/internals/v1"
+)
+
+const (
+	// InvocationChainHeader is the header carrying the comma-separated entries of the apps that a request went
+	// through, from the first to the last hop. Each entry is an app ID or, when loops are detected by method,
+	// an InvocationChainEntry. Like the trace context, apps must forward it on the invocations they make while
+	// handling a request for loops to be detected.
+	InvocationChainHeader = DaprHeaderPrefix + "invocation-chain"
+
+	// DefaultMaxInvocationChainLength is the default maximum number of entries kept in the invocation chain.
+	// The oldest are dropped when it is exceeded, to bound the size of the metadata.
+	DefaultMaxInvocationChainLength = 16
+)
+
+var (
+	trustedInvocationChainsLock sync.Mutex
+	// trustedInvocationChains are the invocation chains of the requests being handled by the app, with the
+	// number of such requests. They are tracked for the whole sidecar, not per request, as the requests that
+	// the app makes cannot be related to the request it is handling.
+	trustedInvocationChains = map[string]int{}
+)
+
+// InvocationChain returns the invocations in the invocation chain of the metadata, from the first
+// to the last hop.
+func InvocationChain(md DaprInternalMetadata) []string {
+	var chain []string
+	for key, val := range md {
+		if CanonicalMetadataKey(key) != InvocationChainHeader {
+			continue
+		}
+		for _, v := range val.GetValues() {
+			for _, entry := range strings.Split(v, ",") {
+				if entry = strings.TrimSpace(entry); entry != "" {
+					chain = append(chain, entry)
+				}
+			}
+		}
+	}
+	return chain
+}
+
+// InvocationChainEntry returns the entry of the invocation chain for an invocation of the method of the app,
+// used when loops are detected by method: the app ID and the escaped method, separated by a slash.
+func InvocationChainEntry(appID, method string) string {
+	return appID + "/" + url.QueryEscape(method)
+}
+
+// DetectInvocationLoop returns true if currentAppID already appears in the invocation chain of the metadata,
+// with any method, which means the request is part of an invocation loop.
+func DetectInvocationLoop(md DaprInternalMetadata, currentAppID string) bool {
+	return slices.ContainsFunc(InvocationChain(md), func(entry string) bool {
+		appID, _, _ := strings.Cut(entry, "/")
+		return appID == currentAppID
+	})
+}
+
+// DetectInvocationMethodLoop returns true if the method of the app already appears in the invocation chain of
+// the metadata, as an InvocationChainEntry. Unlike DetectInvocationLoop, an app may appear in the chain several
+// times with different methods, such as for callbacks; entries with only an app ID never match.
+func DetectInvocationMethodLoop(md DaprInternalMetadata, appID, method string) bool {
+	return slices.Contains(InvocationChain(md), InvocationChainEntry(appID, method))
+}
+
+// SetInvocationChain replaces the invocation chain of the metadata, keeping its last maxLength entries.
+// An empty chain removes it.
+func SetInvocationChain(md DaprInternalMetadata, chain []string, maxLength int) {
+	for key := range md {
+		if CanonicalMetadataKey(key) == InvocationChainHeader {
+			delete(md, key)
+		}
+	}
+	if len(chain) > maxLength {
+		chain = chain[len(chain)-maxLength:]
+	}
+	if len(chain) == 0 {
+		return
+	}
+	md[InvocationChainHeader] = &internalv1pb.ListStringValue{
+		Values: []string{strings.Join(chain, ",")},
+	}
+}
+
+// TrackInvocationChain records the invocation chain of the metadata of a request received from another
+// Dapr sidecar as trusted while the request is handled by the app, until the returned function is called.
+func TrackInvocationChain(md DaprInternalMetadata) func() {
+	chain := InvocationChain(md)
+	if len(chain) == 0 {
+		return func() {}
+	}
+	key := strings.Join(chain, ",")
+
+	trustedInvocationChainsLock.Lock()
+	trustedInvocationChains[key]++
+	trustedInvocationChainsLock.Unlock()
+
+	return func() {
+		trustedInvocationChainsLock.Lock()
+		defer trustedInvocationChainsLock.Unlock()
+		if trustedInvocationChains[key] <= 1 {
+			delete(trustedInvocationChains, key)
+		} else {
+			trustedInvocationChains[key]--
+		}
+	}
+}
+
+// TrustedInvocationChain returns the invocation chain of the metadata of a request received from the app,
+// if it is the chain of a request being handled by the app, forwarded as recorded by TrackInvocationChain.
+// Otherwise, the chain was set outside of the Dapr sidecars, by the app or by a client, and nil is returned.
+// As a result, the chain of a request is lost on the invocations that the app makes while handling it without
+// forwarding the header, such as by an HTTP app that does not copy the dapr-invocation-chain header from the
+// request: each of those starts a new chain, and loops going through the app are not detected.
+func TrustedInvocationChain(md DaprInternalMetadata) []string {
+	chain := InvocationChain(md)
+	if len(chain) == 0 {
+		return nil
+	}
+
+	trustedInvocationChainsLock.Lock()
+	defer trustedInvocationChainsLock.Unlock()
+	if trustedInvocationChains[strings.Join(chain, ",")] == 0 {
+		return nil
+	}
+	return chain
+}
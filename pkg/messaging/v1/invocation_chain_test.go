@@ -0,0 +1,135 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvocationChain(t *testing.T) {
+	t.Run("set and parsed", func(t *testing.T) {
+		md := DaprInternalMetadata{}
+		chain := []string{InvocationChainEntry("app-a", "orders"), InvocationChainEntry("app-b", "pay/card,visa")}
+		SetInvocationChain(md, chain, DefaultMaxInvocationChainLength)
+
+		assert.Equal(t, []string{"app-a/orders,app-b/pay%2Fcard%2Cvisa"}, md[InvocationChainHeader].GetValues())
+		assert.Equal(t, chain, InvocationChain(md))
+	})
+
+	t.Run("HTTP-origin header", func(t *testing.T) {
+		md := DaprInternalMetadata{
+			"Dapr-Invocation-Chain": NewListStringValue("app-a/x, app-b/y", "app-c/z"),
+		}
+		assert.Equal(t, []string{"app-a/x", "app-b/y", "app-c/z"}, InvocationChain(md))
+
+		SetInvocationChain(md, append(InvocationChain(md), "app-d/w"), DefaultMaxInvocationChainLength)
+		require.Len(t, md, 1)
+		assert.Equal(t, []string{"app-a/x,app-b/y,app-c/z,app-d/w"}, md[InvocationChainHeader].GetValues())
+	})
+
+	t.Run("no chain", func(t *testing.T) {
+		assert.Empty(t, InvocationChain(DaprInternalMetadata{}))
+
+		md := DaprInternalMetadata{InvocationChainHeader: NewListStringValue("app-a/x")}
+		SetInvocationChain(md, nil, DefaultMaxInvocationChainLength)
+		assert.Empty(t, md)
+	})
+
+	t.Run("chain length is bounded", func(t *testing.T) {
+		var chain []string
+		for i := range DefaultMaxInvocationChainLength + 2 {
+			chain = append(chain, fmt.Sprintf("app-%d/m", i))
+		}
+		md := DaprInternalMetadata{}
+		SetInvocationChain(md, chain, DefaultMaxInvocationChainLength)
+
+		chain = InvocationChain(md)
+		require.Len(t, chain, DefaultMaxInvocationChainLength)
+		assert.Equal(t, "app-2/m", chain[0])
+		assert.Equal(t, fmt.Sprintf("app-%d/m", DefaultMaxInvocationChainLength+1), chain[len(chain)-1])
+	})
+}
+
+func TestDetectInvocationLoop(t *testing.T) {
+	t.Run("3-hop loop", func(t *testing.T) {
+		md := DaprInternalMetadata{}
+
+		// app-a invokes app-b, which invokes app-c, which invokes app-a again.
+		var chain []string
+		for _, appID := range []string{"app-a", "app-b", "app-c"} {
+			require.False(t, DetectInvocationLoop(md, appID), appID)
+			chain = append(chain, appID)
+			SetInvocationChain(md, chain, DefaultMaxInvocationChainLength)
+		}
+		assert.Equal(t, []string{"app-a,app-b,app-c"}, md[InvocationChainHeader].GetValues())
+
+		assert.True(t, DetectInvocationLoop(md, "app-a"))
+		assert.False(t, DetectInvocationLoop(md, "app-d"))
+	})
+
+	t.Run("entries with methods", func(t *testing.T) {
+		md := DaprInternalMetadata{InvocationChainHeader: NewListStringValue("app-a," + InvocationChainEntry("app-b", "pay"))}
+		assert.True(t, DetectInvocationLoop(md, "app-a"))
+		assert.True(t, DetectInvocationLoop(md, "app-b"))
+		assert.False(t, DetectInvocationLoop(md, "pay"))
+	})
+
+	t.Run("no chain", func(t *testing.T) {
+		assert.False(t, DetectInvocationLoop(nil, "app-a"))
+	})
+}
+
+func TestDetectInvocationMethodLoop(t *testing.T) {
+	md := DaprInternalMetadata{
+		InvocationChainHeader: NewListStringValue("app-a," + InvocationChainEntry("app-b", "pay/card")),
+	}
+	assert.True(t, DetectInvocationMethodLoop(md, "app-b", "pay/card"))
+	assert.False(t, DetectInvocationMethodLoop(md, "app-b", "refund"))
+	// Entries with only an app ID never match a method.
+	assert.False(t, DetectInvocationMethodLoop(md, "app-a", "orders"))
+}
+
+func TestTrustedInvocationChain(t *testing.T) {
+	md := DaprInternalMetadata{InvocationChainHeader: NewListStringValue("app-a/x,app-b/y")}
+
+	t.Run("untracked chain is not trusted", func(t *testing.T) {
+		assert.Nil(t, TrustedInvocationChain(md))
+	})
+
+	t.Run("tracked chain is trusted until released", func(t *testing.T) {
+		release1 := TrackInvocationChain(md)
+		release2 := TrackInvocationChain(DaprInternalMetadata{"Dapr-Invocation-Chain": NewListStringValue("app-a/x", "app-b/y")})
+		assert.Equal(t, []string{"app-a/x", "app-b/y"}, TrustedInvocationChain(md))
+
+		release1()
+		assert.Equal(t, []string{"app-a/x", "app-b/y"}, TrustedInvocationChain(md))
+		release2()
+		assert.Nil(t, TrustedInvocationChain(md))
+	})
+
+	t.Run("other chains are not trusted", func(t *testing.T) {
+		release := TrackInvocationChain(md)
+		defer release()
+		assert.Nil(t, TrustedInvocationChain(DaprInternalMetadata{InvocationChainHeader: NewListStringValue("app-a/x")}))
+	})
+
+	t.Run("no chain", func(t *testing.T) {
+		TrackInvocationChain(DaprInternalMetadata{})()
+		assert.Nil(t, TrustedInvocationChain(DaprInternalMetadata{}))
+	})
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// redactedValue replaces the values of the redacted metadata keys.
+const redactedValue = "[REDACTED]"
+
+// redactedMetadataKeys are the metadata keys carrying credentials, whose values are never
+// included in human-readable output.
+var redactedMetadataKeys = map[string]struct{}{
+	AuthorizationHeader:   {},
+	"proxy-authorization": {},
+	"cookie":              {},
+	"set-cookie":          {},
+	DaprAPITokenHeader:    {},
+}
+
+// FormatMetadata returns a human-readable dump of the metadata for debugging, with one "key: values"
+// line per key. Keys are canonicalized and sorted, and multiple values are joined with commas. The
+// values of the keys carrying credentials are redacted, and the base64-encoded values of binary
+// ("-bin") keys are shown in hex. The output is deterministic for the same metadata.
+func FormatMetadata(md DaprInternalMetadata) string {
+	keys, values := sortedCanonicalMetadata(md, nil)
+
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteString(key)
+		b.WriteString(": ")
+		switch {
+		case isRedactedMetadataKey(key):
+			b.WriteString(redactedValue)
+		case strings.HasSuffix(key, gRPCBinaryMetadataSuffix):
+			for i, v := range values[key] {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				if decoded, err := base64.StdEncoding.DecodeString(v); err == nil {
+					b.WriteString(hex.EncodeToString(decoded))
+				} else {
+					b.WriteString(v)
+				}
+			}
+		default:
+			b.WriteString(strings.Join(values[key], ","))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// isRedactedMetadataKey returns true if the values of the canonical key must be redacted.
+// Keys with the Dapr header prefix, added to permanent HTTP headers over gRPC, are checked without it.
+func isRedactedMetadataKey(key string) bool {
+	if _, ok := redactedMetadataKeys[key]; ok {
+		return true
+	}
+	_, ok := redactedMetadataKeys[strings.TrimPrefix(key, daprHeaderPrefix)]
+	return ok
+}
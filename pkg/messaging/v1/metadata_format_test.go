@@ -0,0 +1,59 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatMetadata(t *testing.T) {
+	md := DaprInternalMetadata{
+		"X-Request-Id":        SingleValue("abc"),
+		"Accept":              NewListStringValue("application/json", "text/plain"),
+		"Authorization":       SingleValue("Bearer secret"),
+		"dapr-api-token":      SingleValue("token"),
+		"dapr-cookie":         SingleValue("session=secret"),
+		"custom-bin":          SingleValue(base64.StdEncoding.EncodeToString([]byte{0x01, 0xab, 0xff})),
+		"invalid-bin":         SingleValue("not base64!"),
+		ContentTypeHeader:     SingleValue(JSONContentType),
+		"x-multi":             SingleValue("b"),
+		"X-Multi":             SingleValue("a"),
+		CallerIDHeader:        SingleValue("app-a"),
+		DestinationIDHeader:   SingleValue("app-b"),
+		"proxy-authorization": SingleValue("Basic secret"),
+	}
+
+	expected := "accept: application/json,text/plain\n" +
+		"authorization: [REDACTED]\n" +
+		"content-type: application/json\n" +
+		"custom-bin: 01abff\n" +
+		"dapr-api-token: [REDACTED]\n" +
+		"dapr-caller-app-id: app-a\n" +
+		"dapr-cookie: [REDACTED]\n" +
+		"destination-app-id: app-b\n" +
+		"invalid-bin: not base64!\n" +
+		"proxy-authorization: [REDACTED]\n" +
+		"x-multi: a,b\n" +
+		"x-request-id: abc\n"
+
+	// The output is the same on every call, despite the random iteration order of the map.
+	for range 10 {
+		assert.Equal(t, expected, FormatMetadata(md))
+	}
+
+	assert.Empty(t, FormatMetadata(nil))
+}
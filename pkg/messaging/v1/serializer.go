@@ -0,0 +1,84 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Serializer converts Protobuf messages to and from the wire format of a content type.
+// ctx is the context of the request, used to record the serialization metrics.
+type Serializer interface {
+	Marshal(ctx context.Context, message proto.Message) ([]byte, error)
+	Unmarshal(ctx context.Context, data []byte, message proto.Message) error
+}
+
+var (
+	serializersLock sync.RWMutex
+	// serializers maps media types, without parameters, to their serializer.
+	serializers = map[string]Serializer{
+		JSONContentType:     jsonSerializer{},
+		ProtobufContentType: protobufSerializer{},
+	}
+)
+
+// RegisterSerializer registers the serializer for a content type. It can be used to support custom
+// content types, or to override the built-in JSON and Protobuf serializers.
+// Parameters of the content type, such as the charset, are ignored.
+func RegisterSerializer(contentType string, s Serializer) {
+	serializersLock.Lock()
+	defer serializersLock.Unlock()
+	serializers[mediaType(contentType)] = s
+}
+
+// SerializerForContentType returns the serializer registered for a content type, and whether there is one.
+// Parameters of the content type, such as the charset, are ignored.
+func SerializerForContentType(contentType string) (Serializer, bool) {
+	serializersLock.RLock()
+	defer serializersLock.RUnlock()
+	s, ok := serializers[mediaType(contentType)]
+	return s, ok
+}
+
+// mediaType returns the lowercased media type of a content type, without parameters.
+func mediaType(contentType string) string {
+	mt, _, _ := strings.Cut(contentType, ";")
+	return CanonicalMetadataKey(mt)
+}
+
+// jsonSerializer is the Serializer for JSONContentType, using ProtobufToJSONContext and JSONToProtobuf.
+type jsonSerializer struct{}
+
+func (jsonSerializer) Marshal(ctx context.Context, message proto.Message) ([]byte, error) {
+	return ProtobufToJSONContext(ctx, message)
+}
+
+func (jsonSerializer) Unmarshal(ctx context.Context, data []byte, message proto.Message) error {
+	return JSONToProtobuf(ctx, data, message)
+}
+
+// protobufSerializer is the Serializer for ProtobufContentType, using the Protobuf wire format.
+type protobufSerializer struct{}
+
+func (protobufSerializer) Marshal(_ context.Context, message proto.Message) ([]byte, error) {
+	return proto.Marshal(message)
+}
+
+func (protobufSerializer) Unmarshal(_ context.Context, data []byte, message proto.Message) error {
+	return proto.Unmarshal(data, message)
+}
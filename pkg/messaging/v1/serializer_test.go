@@ -0,0 +1,84 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+
+	internalv1pb "github.com/dapr/dapr/pkg/proto/internals/v1"
+)
+
+// textSerializer is a custom Serializer using the Protobuf text format.
+type textSerializer struct{}
+
+func (textSerializer) Marshal(_ context.Context, message proto.Message) ([]byte, error) {
+	return prototext.Marshal(message)
+}
+
+func (textSerializer) Unmarshal(_ context.Context, data []byte, message proto.Message) error {
+	return prototext.Unmarshal(data, message)
+}
+
+func TestSerializerForContentType(t *testing.T) {
+	msg := &internalv1pb.ListStringValue{Values: []string{"a", "b"}}
+
+	t.Run("built-in serializers", func(t *testing.T) {
+		for _, contentType := range []string{JSONContentType, "Application/JSON; charset=utf-8", ProtobufContentType} {
+			s, ok := SerializerForContentType(contentType)
+			require.True(t, ok, contentType)
+
+			data, err := s.Marshal(t.Context(), msg)
+			require.NoError(t, err)
+			var res internalv1pb.ListStringValue
+			require.NoError(t, s.Unmarshal(t.Context(), data, &res))
+			assert.True(t, proto.Equal(msg, &res), contentType)
+		}
+
+		s, _ := SerializerForContentType(JSONContentType)
+		data, err := s.Marshal(t.Context(), msg)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"values":["a","b"]}`, string(data))
+	})
+
+	t.Run("unknown content type", func(t *testing.T) {
+		_, ok := SerializerForContentType("application/x-myco")
+		assert.False(t, ok)
+	})
+
+	t.Run("register custom serializer", func(t *testing.T) {
+		t.Cleanup(func() {
+			serializersLock.Lock()
+			delete(serializers, "application/x-myco")
+			serializersLock.Unlock()
+		})
+
+		RegisterSerializer("application/x-myco; version=1", textSerializer{})
+
+		s, ok := SerializerForContentType("application/x-myco")
+		require.True(t, ok)
+		assert.Equal(t, textSerializer{}, s)
+
+		data, err := s.Marshal(t.Context(), msg)
+		require.NoError(t, err)
+		var res internalv1pb.ListStringValue
+		require.NoError(t, s.Unmarshal(t.Context(), data, &res))
+		assert.True(t, proto.Equal(msg, &res))
+	})
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"maps"
+	"sync/atomic"
+)
+
+// tracePropagationDisabledMethods holds the set of methods for which the trace context is not
+// propagated. The set is never modified once stored, so it can be read without locking on every call.
+var tracePropagationDisabledMethods atomic.Pointer[map[string]struct{}]
+
+// fullMethodContextKey is the context key of the method set with WithFullMethod.
+type fullMethodContextKey struct{}
+
+// DisableTracePropagationForMethod disables the propagation of the trace context for the gRPC method, in
+// the "/package.Service/Method" form. When converting the metadata of a call to that method, with
+// InternalMetadataToGrpcMetadata or InternalMetadataToHTTPHeader, the trace headers are dropped and none
+// are injected. This avoids the cost of processing trace headers for high-frequency internal methods,
+// such as health checks.
+func DisableTracePropagationForMethod(fullMethod string) {
+	for {
+		current := tracePropagationDisabledMethods.Load()
+		methods := make(map[string]struct{}, 1)
+		if current != nil {
+			methods = maps.Clone(*current)
+		}
+		methods[fullMethod] = struct{}{}
+		if tracePropagationDisabledMethods.CompareAndSwap(current, &methods) {
+			return
+		}
+	}
+}
+
+// WithFullMethod returns a copy of ctx that carries the gRPC method, in the "/package.Service/Method"
+// form, whose metadata is converted. Callers must set it for DisableTracePropagationForMethod to apply;
+// the method of the gRPC call in the context, if any, is not used.
+func WithFullMethod(ctx context.Context, fullMethod string) context.Context {
+	return context.WithValue(ctx, fullMethodContextKey{}, fullMethod)
+}
+
+// isTracePropagationDisabled returns true if the trace context is not propagated for the method set in
+// ctx with WithFullMethod.
+func isTracePropagationDisabled(ctx context.Context) bool {
+	methods := tracePropagationDisabledMethods.Load()
+	if methods == nil {
+		return false
+	}
+	fullMethod, ok := ctx.Value(fullMethodContextKey{}).(string)
+	if !ok {
+		return false
+	}
+	_, disabled := (*methods)[fullMethod]
+	return disabled
+}
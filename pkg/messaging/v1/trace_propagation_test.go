@@ -0,0 +1,99 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	diagConsts "github.com/dapr/dapr/pkg/diagnostics/consts"
+)
+
+func TestDisableTracePropagationForMethod(t *testing.T) {
+	const (
+		disabledMethod = "/dapr.proto.internals.v1.ServiceInvocation/Health"
+		enabledMethod  = "/dapr.proto.internals.v1.ServiceInvocation/CallLocal"
+		traceparent    = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	)
+
+	previous := tracePropagationDisabledMethods.Load()
+	DisableTracePropagationForMethod(disabledMethod)
+	t.Cleanup(func() {
+		tracePropagationDisabledMethods.Store(previous)
+	})
+
+	md := DaprInternalMetadata{
+		diagConsts.TraceparentHeader: SingleValue(traceparent),
+		diagConsts.TracestateHeader:  SingleValue("congo=t61rcWkgMzE"),
+		"custom-header":              SingleValue("value"),
+	}
+
+	t.Run("http headers", func(t *testing.T) {
+		convert := func(fullMethod string) map[string]string {
+			headers := map[string]string{}
+			InternalMetadataToHTTPHeader(WithFullMethod(t.Context(), fullMethod), md, func(k, v string) {
+				headers[k] = v
+			})
+			return headers
+		}
+
+		headers := convert(disabledMethod)
+		assert.NotContains(t, headers, diagConsts.TraceparentHeader)
+		assert.NotContains(t, headers, diagConsts.TracestateHeader)
+		assert.Equal(t, "value", headers["custom-header"])
+
+		headers = convert(enabledMethod)
+		assert.Equal(t, traceparent, headers[diagConsts.TraceparentHeader])
+		assert.Equal(t, "congo=t61rcWkgMzE", headers[diagConsts.TracestateHeader])
+		assert.Equal(t, "value", headers["custom-header"])
+	})
+
+	t.Run("grpc metadata", func(t *testing.T) {
+		grpcMD := InternalMetadataToGrpcMetadata(WithFullMethod(t.Context(), disabledMethod), md, true)
+		assert.Empty(t, grpcMD.Get(diagConsts.TraceparentHeader))
+		assert.Empty(t, grpcMD.Get(diagConsts.TracestateHeader))
+		assert.Empty(t, grpcMD.Get(diagConsts.GRPCTraceContextKey))
+		assert.Equal(t, []string{"value"}, grpcMD.Get("custom-header"))
+
+		grpcMD = InternalMetadataToGrpcMetadata(WithFullMethod(t.Context(), enabledMethod), md, true)
+		assert.Equal(t, []string{traceparent}, grpcMD.Get(diagConsts.TraceparentHeader))
+		assert.NotEmpty(t, grpcMD.Get(diagConsts.GRPCTraceContextKey))
+		assert.Equal(t, []string{"value"}, grpcMD.Get("custom-header"))
+	})
+
+	t.Run("no method in context", func(t *testing.T) {
+		headers := map[string]string{}
+		InternalMetadataToHTTPHeader(t.Context(), md, func(k, v string) {
+			headers[k] = v
+		})
+		assert.Equal(t, traceparent, headers[diagConsts.TraceparentHeader])
+	})
+
+	t.Run("method of the grpc call is not used", func(t *testing.T) {
+		ctx := grpc.NewContextWithServerTransportStream(t.Context(), &fakeServerTransportStream{method: disabledMethod})
+		grpcMD := InternalMetadataToGrpcMetadata(ctx, md, true)
+		assert.Equal(t, []string{traceparent}, grpcMD.Get(diagConsts.TraceparentHeader))
+	})
+}
+
+type fakeServerTransportStream struct {
+	grpc.ServerTransportStream
+	method string
+}
+
+func (s *fakeServerTransportStream) Method() string {
+	return s.method
+}
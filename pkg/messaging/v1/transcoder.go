@@ -0,0 +1,48 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// NDJSONContentType is the MIME media type for newline-delimited JSON.
+const NDJSONContentType = "application/x-ndjson"
+
+// StreamTranscoder converts each message of a stream, such as a gRPC server-streaming response,
+// to a chunk of the body of an HTTP response of another content type.
+type StreamTranscoder interface {
+	Transcode(msg proto.Message) ([]byte, error)
+}
+
+// NewNDJSONTranscoder returns a StreamTranscoder to NDJSONContentType, which converts each message to
+// a line of JSON. ctx is the context of the stream, used to record the serialization metrics.
+func NewNDJSONTranscoder(ctx context.Context) StreamTranscoder {
+	return ndjsonTranscoder{ctx: ctx}
+}
+
+// ndjsonTranscoder is the StreamTranscoder returned by NewNDJSONTranscoder.
+type ndjsonTranscoder struct {
+	ctx context.Context
+}
+
+func (t ndjsonTranscoder) Transcode(msg proto.Message) ([]byte, error) {
+	b, err := ProtobufToJSONContext(t.ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
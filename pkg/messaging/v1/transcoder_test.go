@@ -0,0 +1,42 @@
+/*
+Copyright 2026 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	internalv1pb "github.com/dapr/dapr/pkg/proto/internals/v1"
+)
+
+func TestNDJSONTranscoder(t *testing.T) {
+	transcoder := NewNDJSONTranscoder(t.Context())
+
+	var body bytes.Buffer
+	for _, values := range [][]string{{"a"}, {"b", "c"}, {"d"}} {
+		chunk, err := transcoder.Transcode(&internalv1pb.ListStringValue{Values: values})
+		require.NoError(t, err)
+		assert.Equal(t, byte('\n'), chunk[len(chunk)-1])
+		body.Write(chunk)
+	}
+
+	lines := bytes.Split(bytes.TrimSuffix(body.Bytes(), []byte("\n")), []byte("\n"))
+	require.Len(t, lines, 3)
+	assert.JSONEq(t, `{"values":["a"]}`, string(lines[0]))
+	assert.JSONEq(t, `{"values":["b","c"]}`, string(lines[1]))
+	assert.JSONEq(t, `{"values":["d"]}`, string(lines[2]))
+}
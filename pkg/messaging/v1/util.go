@@ -14,26 +14,38 @@ limitations under the License.
 package v1
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
+	"fmt"
+	"io"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"go.opentelemetry.io/otel/trace"
+	rpccode "google.golang.org/genproto/googleapis/rpc/code"
 	epb "google.golang.org/genproto/googleapis/rpc/errdetails"
 	spb "google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	grpcStatus "google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
 
 	diag "github.com/dapr/dapr/pkg/diagnostics"
 	diagConsts "github.com/dapr/dapr/pkg/diagnostics/consts"
 	diagUtils "github.com/dapr/dapr/pkg/diagnostics/utils"
 	internalv1pb "github.com/dapr/dapr/pkg/proto/internals/v1"
+	securityConsts "github.com/dapr/dapr/pkg/security/consts"
+	"github.com/dapr/dapr/pkg/security/spiffe"
+	"github.com/dapr/kit/logger"
 )
 
 const (
@@ -58,6 +70,41 @@ const (
 	// gRPCBinaryMetadata is the suffix of grpc metadata binary value.
 	gRPCBinaryMetadataSuffix = "-bin"
 
+	// GRPCAcceptEncodingHeader is the header key of grpc-accept-encoding.
+	GRPCAcceptEncodingHeader = "grpc-accept-encoding"
+	// AcceptEncodingHeader is the header key of accept-encoding.
+	AcceptEncodingHeader = "accept-encoding"
+	// ForwardedForHeader is the header key of x-forwarded-for.
+	ForwardedForHeader = "x-forwarded-for"
+	// RealIPHeader is the header key of x-real-ip.
+	RealIPHeader = "x-real-ip"
+	// PreferHeader is the header key of prefer.
+	PreferHeader = "prefer"
+	// TransferEncodingHeader is the header key of transfer-encoding.
+	TransferEncodingHeader = "transfer-encoding"
+	// AuthorizationHeader is the header key of authorization.
+	AuthorizationHeader = "authorization"
+	// ExpectHeader is the header key of expect.
+	ExpectHeader = "expect"
+	// expectContinueValue is the only expectation defined by RFC 7231 Section 5.1.1.
+	expectContinueValue = "100-continue"
+	// LinkHeader is the header key of link (RFC 8288). It is an end-to-end header that is
+	// neither reserved nor permanent, so it is forwarded unprefixed with all its values.
+	LinkHeader = "link"
+
+	// OriginHeader, AccessControlRequestMethodHeader, and AccessControlRequestHeadersHeader are the
+	// header keys of the CORS request headers. They are end-to-end headers, forwarded to the app.
+	OriginHeader                      = "origin"
+	AccessControlRequestMethodHeader  = "access-control-request-method"
+	AccessControlRequestHeadersHeader = "access-control-request-headers"
+
+	// MethodHeader is the header carrying the HTTP method of the request a response was produced for.
+	// It is set on responses to HEAD requests, which have no body, and is never forwarded.
+	MethodHeader = DaprHeaderPrefix + "method"
+
+	// DaprAPITokenHeader is the header carrying the Dapr API token. It is never forwarded downstream.
+	DaprAPITokenHeader = securityConsts.APITokenHeader
+
 	// DestinationIDHeader is the header carrying the value of the invoked app id.
 	DestinationIDHeader = "destination-app-id"
 
@@ -65,11 +112,18 @@ const (
 	// https://github.com/googleapis/googleapis/blob/master/google/rpc/error_details.proto#L126
 	maxMetadataValueLen = 63
 
+	// maxStatusBodyLen is the maximum size of a JSON error body read to decode a google.rpc.Status.
+	maxStatusBodyLen = 64 << 10
+
 	// ErrorInfo metadata for HTTP response.
 	errorInfoDomain            = "dapr.io"
 	errorInfoHTTPCodeMetadata  = "http.code"
 	errorInfoHTTPErrorMetadata = "http.error_message"
 
+	// ErrorInfo reason and metadata for streams that failed after delivering some messages.
+	errorInfoPartialStreamReason  = "PARTIAL_STREAM"
+	errorInfoSentMessagesMetadata = "stream.sent_messages"
+
 	CallerNamespaceHeader = DaprHeaderPrefix + "caller-namespace"
 	CallerIDHeader        = DaprHeaderPrefix + "caller-app-id"
 	CalleeIDHeader        = DaprHeaderPrefix + "callee-app-id"
@@ -85,15 +139,198 @@ var BufPool = sync.Pool{
 	},
 }
 
+// daprHeaderPrefix is the prefix applied to reserved headers when they are forwarded.
+// It defaults to DaprHeaderPrefix and can be changed with SetDaprHeaderPrefix.
+var daprHeaderPrefix = DaprHeaderPrefix
+
+// b3Propagation enables accepting and emitting B3 trace context headers, in addition to W3C trace context.
+var b3Propagation bool
+
+// SetB3Propagation sets whether B3 trace context headers are accepted and emitted when converting metadata.
+// When enabled, B3 headers are used as the trace context if no traceparent is present, and the outgoing
+// trace context is also emitted as multi-header B3. This is not safe for concurrent use and should be
+// called during initialization, before any metadata is converted.
+func SetB3Propagation(enabled bool) {
+	b3Propagation = enabled
+}
+
+var log = logger.NewLogger("dapr.runtime.messaging")
+
+// strictTraceparent enables dropping malformed traceparent headers on HTTP to HTTP calls.
+var strictTraceparent bool
+
+// SetStrictTraceparent sets whether a malformed traceparent header received on an HTTP to HTTP call is
+// dropped, with a warning, instead of being passed through as is, so broken instrumentation is visible.
+// This is not safe for concurrent use and should be called during initialization, before any metadata is converted.
+func SetStrictTraceparent(enabled bool) {
+	strictTraceparent = enabled
+}
+
+// forwardAuthorizationHeader enables forwarding the Authorization header across hops.
+var forwardAuthorizationHeader = true
+
+// SetForwardAuthorizationHeader sets whether the Authorization header is forwarded when converting metadata.
+// It is forwarded by default; disabling it prevents credentials from leaking across app boundaries.
+// This is not safe for concurrent use and should be called during initialization, before any metadata is converted.
+func SetForwardAuthorizationHeader(enabled bool) {
+	forwardAuthorizationHeader = enabled
+}
+
+// SetDaprHeaderPrefix sets the prefix applied to reserved headers when they are forwarded.
+// The prefix must end with "-". This is not safe for concurrent use and should be called
+// during initialization, before any metadata is converted.
+func SetDaprHeaderPrefix(prefix string) error {
+	if len(prefix) < 2 || !strings.HasSuffix(prefix, "-") {
+		return fmt.Errorf("invalid header prefix %q: must be non-empty and end with '-'", prefix)
+	}
+	daprHeaderPrefix = strings.ToLower(prefix)
+	return nil
+}
+
+// defaultContentType is the content type of messages without a content-type header.
+var defaultContentType = OctetStreamContentType
+
+// SetDefaultContentType sets the content type returned by EffectiveContentType for messages without a
+// content-type header. It defaults to OctetStreamContentType. This is not safe for concurrent use and
+// should be called during initialization, before any metadata is converted.
+func SetDefaultContentType(ct string) {
+	defaultContentType = ct
+}
+
 // DaprInternalMetadata is the metadata type to transfer HTTP header and gRPC metadata
 // from user app to Dapr.
 type DaprInternalMetadata map[string]*internalv1pb.ListStringValue
 
+// NewListStringValue returns a ListStringValue holding values, in order.
+func NewListStringValue(values ...string) *internalv1pb.ListStringValue {
+	return &internalv1pb.ListStringValue{
+		Values: values,
+	}
+}
+
+// SingleValue returns a ListStringValue holding the single value v.
+func SingleValue(v string) *internalv1pb.ListStringValue {
+	return NewListStringValue(v)
+}
+
+// CanonicalMetadataKey returns the canonical form of a metadata key or header name, lowercased and
+// without surrounding whitespace, that is used internally to compare keys.
+func CanonicalMetadataKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+// CoalesceMetadataKeys returns a copy of md in which the keys that only differ in case, such as
+// "Content-Type" and "content-type", are merged into a single key in their canonical form. The values
+// of merged keys are concatenated in the sorted order of the original keys, so the result is stable.
+func CoalesceMetadataKeys(md DaprInternalMetadata) DaprInternalMetadata {
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	res := make(DaprInternalMetadata, len(md))
+	for _, k := range keys {
+		key := CanonicalMetadataKey(k)
+		if existing, ok := res[key]; ok {
+			res[key] = NewListStringValue(slices.Concat(existing.GetValues(), md[k].GetValues())...)
+			continue
+		}
+		res[key] = NewListStringValue(md[k].GetValues()...)
+	}
+	return res
+}
+
+// sortedCanonicalMetadata returns the sorted canonical keys of md, excluding those in excluded, and
+// their values. Keys that only differ in case are merged, and their values are sorted as the order in
+// which they are iterated is not stable.
+func sortedCanonicalMetadata(md DaprInternalMetadata, excluded map[string]struct{}) ([]string, map[string][]string) {
+	keys := make([]string, 0, len(md))
+	values := make(map[string][]string, len(md))
+	for k, v := range md {
+		key := CanonicalMetadataKey(k)
+		if _, ok := excluded[key]; ok {
+			continue
+		}
+		if existing, ok := values[key]; ok {
+			merged := slices.Concat(existing, v.GetValues())
+			slices.Sort(merged)
+			values[key] = merged
+			continue
+		}
+		keys = append(keys, key)
+		values[key] = v.GetValues()
+	}
+	slices.Sort(keys)
+	return keys, values
+}
+
 // IsJSONContentType returns true if contentType is the mime media type for JSON.
 func IsJSONContentType(contentType string) bool {
 	return strings.HasPrefix(strings.ToLower(contentType), JSONContentType)
 }
 
+// IsTextContentType returns true if contentType is a text-based mime media type, such as JSON, XML,
+// form-encoded data, or any text/* type, whose bodies are safe to log. Protobuf, octet-stream, and
+// other binary types return false.
+func IsTextContentType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case mediaType == JSONContentType, mediaType == "application/xml", mediaType == "application/x-www-form-urlencoded":
+		return true
+	case strings.HasSuffix(mediaType, "+json"), strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	return false
+}
+
+// SniffContentType guesses the content type of a body that was sent without one.
+// JSON objects and arrays are detected by their leading character, and bodies that parse
+// as a sequence of well-formed protobuf fields are assumed to be protobuf. Otherwise the
+// result of http.DetectContentType is used, which falls back to OctetStreamContentType.
+func SniffContentType(body []byte) string {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	if len(trimmed) == 0 {
+		return OctetStreamContentType
+	}
+	if trimmed[0] == '{' || trimmed[0] == '[' {
+		return JSONContentType
+	}
+
+	detected := http.DetectContentType(body)
+	if detected != OctetStreamContentType {
+		return detected
+	}
+	if isProtobufWireFormat(body) {
+		return ProtobufContentType
+	}
+	return OctetStreamContentType
+}
+
+// isProtobufWireFormat returns true if b is entirely made of valid protobuf fields.
+func isProtobufWireFormat(b []byte) bool {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 || num < 1 {
+			return false
+		}
+		b = b[n:]
+		// Groups are deprecated and unlikely in Dapr payloads.
+		if typ == protowire.StartGroupType || typ == protowire.EndGroupType {
+			return false
+		}
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			return false
+		}
+		b = b[n:]
+	}
+	return true
+}
+
 // IsHopByHopHeader returns true if the header is a hop-by-hop header
 // that must not be forwarded by proxies per RFC 7230 Section 6.1.
 func IsHopByHopHeader(hdr string) bool {
@@ -101,7 +338,7 @@ func IsHopByHopHeader(hdr string) bool {
 	case "connection",
 		"keep-alive",
 		"proxy-connection",
-		"transfer-encoding",
+		TransferEncodingHeader,
 		"upgrade",
 		"http2-settings",
 		"te",
@@ -113,6 +350,27 @@ func IsHopByHopHeader(hdr string) bool {
 	return false
 }
 
+// IsChunkedTransfer returns true if the metadata carries a Transfer-Encoding header whose final
+// coding is chunked, as in responses streamed by HTTP/1.1 apps. The header itself is hop-by-hop
+// and is never forwarded, so this is how its presence is surfaced.
+func IsChunkedTransfer(md DaprInternalMetadata) bool {
+	for key, val := range md {
+		if CanonicalMetadataKey(key) != TransferEncodingHeader {
+			continue
+		}
+		values := val.GetValues()
+		if len(values) == 0 {
+			continue
+		}
+		// Per RFC 7230 Section 3.3.1, chunked must be the final coding.
+		codings := strings.Split(values[len(values)-1], ",")
+		if strings.EqualFold(strings.TrimSpace(codings[len(codings)-1]), "chunked") {
+			return true
+		}
+	}
+	return false
+}
+
 // connectionHopByHopHeaders returns the set of header names nominated as
 // hop-by-hop by the Connection header value per RFC 7230 Section 6.1.
 func connectionHopByHopHeaders(internalMD DaprInternalMetadata) map[string]struct{} {
@@ -138,6 +396,298 @@ func connectionHopByHopHeaders(internalMD DaprInternalMetadata) map[string]struc
 	return headers
 }
 
+// WantsContinue returns true if the metadata carries an "Expect: 100-continue" header.
+// The expectation is handled by Dapr's HTTP server and is never forwarded to the app.
+func WantsContinue(md DaprInternalMetadata) bool {
+	for key, val := range md {
+		if !strings.EqualFold(key, ExpectHeader) {
+			continue
+		}
+		for _, v := range val.GetValues() {
+			if isExpectContinue(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isExpectContinue(val string) bool {
+	return strings.EqualFold(strings.TrimSpace(val), expectContinueValue)
+}
+
+// RemoveMethodHeader removes the MethodHeader marker from metadata received from the app, so that an app
+// response cannot forge it. The app channels call it before setting the marker themselves.
+func RemoveMethodHeader(md DaprInternalMetadata) {
+	for key := range md {
+		if CanonicalMetadataKey(key) == MethodHeader {
+			delete(md, key)
+		}
+	}
+}
+
+// IsHeadResponse returns true if the metadata carries the MethodHeader marker of a response to a HEAD request.
+// The marker is set by the app channels only, which remove it from the app responses with RemoveMethodHeader.
+func IsHeadResponse(md DaprInternalMetadata) bool {
+	for key, val := range md {
+		if CanonicalMetadataKey(key) != MethodHeader {
+			continue
+		}
+		for _, v := range val.GetValues() {
+			if strings.EqualFold(strings.TrimSpace(v), http.MethodHead) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsCORSPreflight returns true if the metadata is of a CORS preflight request: an HTTP OPTIONS
+// request with an Access-Control-Request-Method header. Preflights are sent by browsers before
+// cross-origin requests, and can be answered without invoking the app.
+func IsCORSPreflight(md DaprInternalMetadata) bool {
+	var options, requestMethod bool
+	for key, val := range md {
+		if len(val.GetValues()) == 0 {
+			continue
+		}
+		switch CanonicalMetadataKey(key) {
+		case ":method":
+			options = strings.EqualFold(strings.TrimSpace(val.GetValues()[0]), http.MethodOptions)
+		case AccessControlRequestMethodHeader:
+			requestMethod = strings.TrimSpace(val.GetValues()[0]) != ""
+		}
+	}
+	return options && requestMethod
+}
+
+// HTTPMethodFromMetadata returns the HTTP method of an HTTP-origin request or response from the reserved
+// ":method" metadata, or else the MethodHeader, in upper case. It returns an empty string for
+// gRPC-origin metadata, or if neither is set.
+func HTTPMethodFromMetadata(md DaprInternalMetadata) string {
+	if IsGRPCProtocol(md) {
+		return ""
+	}
+	var method string
+	for key, val := range md {
+		if len(val.GetValues()) == 0 {
+			continue
+		}
+		switch CanonicalMetadataKey(key) {
+		case ":method":
+			return strings.ToUpper(strings.TrimSpace(val.GetValues()[0]))
+		case MethodHeader:
+			method = strings.ToUpper(strings.TrimSpace(val.GetValues()[0]))
+		}
+	}
+	return method
+}
+
+// Protocol versions returned by ProtocolVersionFromMetadata.
+const (
+	ProtocolVersionHTTP11 = "HTTP/1.1"
+	ProtocolVersionHTTP2  = "HTTP/2"
+)
+
+// ProtocolVersionFromMetadata returns the HTTP protocol version, ProtocolVersionHTTP2 or
+// ProtocolVersionHTTP11, over which the request or response carried by the metadata originated.
+// gRPC-origin metadata, and metadata with HTTP/2 pseudo-headers such as ":method" or ":authority",
+// are HTTP/2; all other metadata is assumed to be HTTP/1.1. The returned value has a low
+// cardinality, so it is suitable as a metric tag.
+func ProtocolVersionFromMetadata(md DaprInternalMetadata) string {
+	if IsGRPCProtocol(md) {
+		return ProtocolVersionHTTP2
+	}
+	for key := range md {
+		if strings.HasPrefix(key, ":") {
+			return ProtocolVersionHTTP2
+		}
+	}
+	return ProtocolVersionHTTP11
+}
+
+// ResolveContentType returns the content type of a message from its content-type header, or sniffs it
+// from the body if the header is not set. Responses to HEAD requests have no body, so their content-type
+// header is trusted and the empty body is not sniffed.
+func ResolveContentType(md DaprInternalMetadata, body []byte) string {
+	var contentType string
+	for key, val := range md {
+		if CanonicalMetadataKey(key) == ContentTypeHeader && len(val.GetValues()) > 0 {
+			contentType = val.GetValues()[0]
+			break
+		}
+	}
+
+	if contentType != "" || IsHeadResponse(md) {
+		return contentType
+	}
+	return SniffContentType(body)
+}
+
+// EffectiveContentType returns the content type of a message from its content-type header,
+// or the default content type set with SetDefaultContentType if the header is not set.
+func EffectiveContentType(md DaprInternalMetadata) string {
+	for key, val := range md {
+		if CanonicalMetadataKey(key) == ContentTypeHeader && len(val.GetValues()) > 0 && val.GetValues()[0] != "" {
+			return val.GetValues()[0]
+		}
+	}
+	return defaultContentType
+}
+
+// PreferDirectives parses the Prefer headers in the metadata into a map of preference
+// names to values, per RFC 7240. Names are lowercased, preferences without a value map
+// to an empty string, and parameters are ignored. If a preference is repeated, the
+// first occurrence takes precedence.
+func PreferDirectives(md DaprInternalMetadata) map[string]string {
+	directives := map[string]string{}
+	for key, val := range md {
+		if !strings.EqualFold(key, PreferHeader) {
+			continue
+		}
+		for _, v := range val.GetValues() {
+			for pref := range strings.SplitSeq(v, ",") {
+				// Strip the parameters.
+				pref, _, _ = strings.Cut(pref, ";")
+				name, value, _ := strings.Cut(pref, "=")
+				name = strings.ToLower(strings.TrimSpace(name))
+				if name == "" {
+					continue
+				}
+				if _, ok := directives[name]; ok {
+					continue
+				}
+				directives[name] = strings.Trim(strings.TrimSpace(value), `"`)
+			}
+		}
+	}
+	return directives
+}
+
+// AcceptsEncoding returns true if the caller advertised support for the named content encoding
+// in the grpc-accept-encoding or accept-encoding headers. The wildcard "*" and q-values are honored,
+// as per RFC 7231 Section 5.3.4; an encoding with q=0 is not acceptable. "identity" is acceptable
+// unless it is explicitly excluded.
+func AcceptsEncoding(md DaprInternalMetadata, encoding string) bool {
+	var (
+		found, wildcardFound bool
+		accepted, wildcard   bool
+	)
+	for key, val := range md {
+		if !strings.EqualFold(key, GRPCAcceptEncodingHeader) && !strings.EqualFold(key, AcceptEncodingHeader) {
+			continue
+		}
+		for _, v := range val.GetValues() {
+			for coding := range strings.SplitSeq(v, ",") {
+				name, params, _ := strings.Cut(coding, ";")
+				name = strings.TrimSpace(name)
+				ok := qValueAcceptable(params)
+				switch {
+				case strings.EqualFold(name, encoding):
+					// An explicit exclusion takes precedence.
+					accepted = (!found || accepted) && ok
+					found = true
+				case name == "*":
+					wildcard = (!wildcardFound || wildcard) && ok
+					wildcardFound = true
+				}
+			}
+		}
+	}
+
+	switch {
+	case found:
+		return accepted
+	case wildcardFound:
+		return wildcard
+	default:
+		return strings.EqualFold(encoding, "identity")
+	}
+}
+
+// qValueAcceptable returns false if the parameters of an Accept-Encoding coding set q=0.
+func qValueAcceptable(params string) bool {
+	for param := range strings.SplitSeq(params, ";") {
+		name, value, _ := strings.Cut(param, "=")
+		if !strings.EqualFold(strings.TrimSpace(name), "q") {
+			continue
+		}
+		q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		return err == nil && q > 0
+	}
+	return true
+}
+
+// ClientIPFromMetadata returns the IP of the originating client, read from the leftmost
+// x-forwarded-for entry or from x-real-ip. It returns an empty string if neither is present.
+// These headers are set by the client and the proxies in front of Dapr, so the value must
+// not be trusted for authorization.
+func ClientIPFromMetadata(md DaprInternalMetadata) string {
+	var realIP string
+	for key, val := range md {
+		if len(val.GetValues()) == 0 {
+			continue
+		}
+		switch {
+		case strings.EqualFold(key, ForwardedForHeader):
+			first, _, _ := strings.Cut(val.GetValues()[0], ",")
+			if ip := strings.TrimSpace(first); ip != "" {
+				return ip
+			}
+		case strings.EqualFold(key, RealIPHeader):
+			realIP = strings.TrimSpace(val.GetValues()[0])
+		}
+	}
+	return realIP
+}
+
+// APITokenFromMetadata returns the Dapr API token in the metadata, and whether it is present.
+func APITokenFromMetadata(md DaprInternalMetadata) (string, bool) {
+	for key, val := range md {
+		if CanonicalMetadataKey(key) == DaprAPITokenHeader && len(val.GetValues()) > 0 {
+			return val.GetValues()[0], true
+		}
+	}
+	return "", false
+}
+
+// VerifyCallerNamespace cross-checks the caller namespace header in the metadata against the namespace
+// of the peer's verified SPIFFE identity, to prevent a caller from spoofing its namespace. It returns a
+// PermissionDenied status error if the namespaces differ, or if the header is set but the peer has no
+// SPIFFE identity to verify it against. Metadata without the header is accepted.
+func VerifyCallerNamespace(ctx context.Context, md DaprInternalMetadata) error {
+	// id is nil if the peer has no SPIFFE identity.
+	id, _, err := spiffe.FromGRPCContext(ctx)
+	if err != nil {
+		return grpcStatus.Errorf(codes.PermissionDenied, "failed to parse the caller SPIFFE identity: %v", err)
+	}
+	return verifyCallerNamespace(id, md)
+}
+
+// verifyCallerNamespace checks the caller namespace header in md against the namespace of id,
+// which is nil if the peer has no SPIFFE identity.
+func verifyCallerNamespace(id *spiffe.Parsed, md DaprInternalMetadata) error {
+	var namespace string
+	var found bool
+	for key, val := range md {
+		if CanonicalMetadataKey(key) == CallerNamespaceHeader && len(val.GetValues()) > 0 {
+			namespace, found = val.GetValues()[0], true
+			break
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	if id == nil {
+		return grpcStatus.Errorf(codes.PermissionDenied, "caller namespace %q cannot be verified: the caller has no SPIFFE identity", namespace)
+	}
+	if id.Namespace() != namespace {
+		return grpcStatus.Errorf(codes.PermissionDenied, "caller namespace %q does not match the namespace %q of the caller SPIFFE identity", namespace, id.Namespace())
+	}
+	return nil
+}
+
 // isPermanentHTTPHeader checks whether hdr belongs to the list of
 // permanent request headers maintained by IANA.
 // http://www.iana.org/assignments/message-headers/message-headers.xml
@@ -147,7 +697,6 @@ func isPermanentHTTPHeader(hdr string) bool {
 		"Accept",
 		"Accept-Charset",
 		"Accept-Language",
-		"Accept-Ranges",
 		// Connection-specific header fields such as Connection and Keep-Alive are prohibited in HTTP/2.
 		// See https://tools.ietf.org/html/rfc7540#section-8.1.2.2.
 		"Connection",
@@ -184,10 +733,20 @@ func isPermanentHTTPHeader(hdr string) bool {
 
 // InternalMetadataToGrpcMetadata converts internal metadata map to gRPC metadata.
 func InternalMetadataToGrpcMetadata(ctx context.Context, internalMD DaprInternalMetadata, httpHeaderConversion bool) metadata.MD {
-	var traceparentValue, tracestateValue, grpctracebinValue string
+	if diag.DefaultGRPCMonitoring.MetadataConversionMetricsEnabled() {
+		defer diag.DefaultGRPCMonitoring.MetadataConversionCompleted(ctx, "to_grpc", time.Now())
+	}
+
+	var traceparentValue, tracestateValue string
+	var grpctracebinValues []string
+	var b3Headers map[string]string
 	md := metadata.MD{}
 	for k, listVal := range internalMD {
-		keyName := strings.ToLower(k)
+		keyName := CanonicalMetadataKey(k)
+		if b3Propagation && isB3Header(keyName) {
+			b3Headers = collectB3Header(b3Headers, keyName, listVal)
+			continue
+		}
 		// get both the trace headers for HTTP/GRPC and continue
 		switch keyName {
 		case diagConsts.TraceparentHeader:
@@ -197,17 +756,29 @@ func InternalMetadataToGrpcMetadata(ctx context.Context, internalMD DaprInternal
 			tracestateValue = listVal.GetValues()[0]
 			continue
 		case diagConsts.GRPCTraceContextKey:
-			grpctracebinValue = listVal.GetValues()[0]
+			grpctracebinValues = listVal.GetValues()
 			continue
-		case DestinationIDHeader:
+		case DestinationIDHeader, DaprAPITokenHeader, MethodHeader:
 			continue
+		case TransferEncodingHeader:
+			// Transfer-Encoding is hop-by-hop and prohibited in HTTP/2; see IsChunkedTransfer.
+			continue
+		case AuthorizationHeader:
+			if !forwardAuthorizationHeader {
+				continue
+			}
+		case ExpectHeader:
+			// Expect: 100-continue is consumed by Dapr, not forwarded.
+			if len(listVal.GetValues()) > 0 && isExpectContinue(listVal.GetValues()[0]) {
+				continue
+			}
 		}
 
 		if httpHeaderConversion && isPermanentHTTPHeader(k) {
-			keyName = DaprHeaderPrefix + keyName
+			keyName = daprHeaderPrefix + keyName
 		}
 
-		if strings.HasSuffix(k, gRPCBinaryMetadataSuffix) {
+		if strings.HasSuffix(keyName, gRPCBinaryMetadataSuffix) {
 			// decoded base64 encoded key binary
 			for _, val := range listVal.GetValues() {
 				decoded, err := base64.StdEncoding.DecodeString(val)
@@ -220,15 +791,74 @@ func InternalMetadataToGrpcMetadata(ctx context.Context, internalMD DaprInternal
 		}
 	}
 
+	if isTracePropagationDisabled(ctx) {
+		return md
+	}
 	if IsGRPCProtocol(internalMD) {
-		processGRPCToGRPCTraceHeader(ctx, md, grpctracebinValue)
+		processGRPCToGRPCTraceHeader(ctx, md, grpctracebinValues)
 	} else {
+		if traceparentValue == "" {
+			traceparentValue = traceparentFromB3(b3Headers)
+		}
 		// if HTTP protocol, then pass HTTP traceparent and HTTP tracestate header values, attach it in grpc-trace-bin header
 		processHTTPToGRPCTraceHeader(ctx, md, traceparentValue, tracestateValue)
 	}
+	if b3Propagation {
+		if vals := md.Get(diagConsts.TraceparentHeader); len(vals) > 0 {
+			setB3FromTraceparent(vals[0], func(header, value string) {
+				md.Set(header, value)
+			})
+		}
+	}
 	return md
 }
 
+// isB3Header returns true if key is one of the B3 trace context headers.
+func isB3Header(key string) bool {
+	switch key {
+	case diagConsts.B3SingleHeader,
+		diagConsts.B3TraceIDHeader,
+		diagConsts.B3SpanIDHeader,
+		diagConsts.B3ParentSpanIDHeader,
+		diagConsts.B3SampledHeader,
+		diagConsts.B3FlagsHeader:
+		return true
+	}
+	return false
+}
+
+func collectB3Header(b3Headers map[string]string, key string, listVal *internalv1pb.ListStringValue) map[string]string {
+	if len(listVal.GetValues()) == 0 {
+		return b3Headers
+	}
+	if b3Headers == nil {
+		b3Headers = make(map[string]string, 4)
+	}
+	b3Headers[key] = listVal.GetValues()[0]
+	return b3Headers
+}
+
+// traceparentFromB3 returns the W3C traceparent equivalent to the B3 headers, or an empty string.
+func traceparentFromB3(b3Headers map[string]string) string {
+	if len(b3Headers) == 0 {
+		return ""
+	}
+	sc, ok := diagUtils.SpanContextFromB3(func(key string) string {
+		return b3Headers[key]
+	})
+	if !ok {
+		return ""
+	}
+	return diag.SpanContextToW3CString(sc)
+}
+
+// setB3FromTraceparent emits the multi-header B3 equivalent of the W3C traceparent using setHeader.
+func setB3FromTraceparent(traceparent string, setHeader func(string, string)) {
+	if sc, ok := diag.SpanContextFromW3CString(traceparent); ok {
+		diagUtils.B3FromSpanContext(sc, setHeader, false)
+	}
+}
+
 // IsGRPCProtocol checks if metadata is originated from gRPC API.
 func IsGRPCProtocol(internalMD DaprInternalMetadata) bool {
 	originContentType := ""
@@ -238,13 +868,30 @@ func IsGRPCProtocol(internalMD DaprInternalMetadata) bool {
 	return strings.HasPrefix(originContentType, GRPCContentType)
 }
 
+// HasTraceContext returns true if the metadata carries a traceparent or grpc-trace-bin value.
+// The values are not decoded nor validated.
+func HasTraceContext(internalMD DaprInternalMetadata) bool {
+	if _, ok := internalMD[diagConsts.TraceparentHeader]; ok {
+		return true
+	}
+	if _, ok := internalMD[diagConsts.GRPCTraceContextKey]; ok {
+		return true
+	}
+	for k := range internalMD {
+		if strings.EqualFold(k, diagConsts.TraceparentHeader) || strings.EqualFold(k, diagConsts.GRPCTraceContextKey) {
+			return true
+		}
+	}
+	return false
+}
+
 func ReservedGRPCMetadataToDaprPrefixHeader(key string) string {
 	// https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-HTTP2.md
 	if key == ":method" || key == ":scheme" || key == ":path" || key == ":authority" {
-		return DaprHeaderPrefix + key[1:]
+		return daprHeaderPrefix + key[1:]
 	}
 	if strings.HasPrefix(key, "grpc-") {
-		return DaprHeaderPrefix + key
+		return daprHeaderPrefix + key
 	}
 
 	return key
@@ -252,17 +899,39 @@ func ReservedGRPCMetadataToDaprPrefixHeader(key string) string {
 
 // InternalMetadataToHTTPHeader converts internal metadata pb to HTTP headers.
 func InternalMetadataToHTTPHeader(ctx context.Context, internalMD DaprInternalMetadata, setHeader func(string, string)) {
+	if diag.DefaultGRPCMonitoring.MetadataConversionMetricsEnabled() {
+		defer diag.DefaultGRPCMonitoring.MetadataConversionCompleted(ctx, "to_http", time.Now())
+	}
+
 	// Build the set of headers nominated by the Connection header value
 	// per RFC 7230 Section 6.1.
 	connHopByHop := connectionHopByHopHeaders(internalMD)
+	headResponse := IsHeadResponse(internalMD)
+
+	var trimmed map[string]struct{}
+	if maxResponseHeaderSize > 0 {
+		trimmed = headersToTrim(internalMD, maxResponseHeaderSize)
+		if len(trimmed) > 0 {
+			diag.DefaultGRPCMonitoring.HeadersTrimmed(ctx)
+		}
+	}
 
-	var traceparentValue, tracestateValue, grpctracebinValue string
+	var traceparentValue, tracestateValue string
+	var grpctracebinValues []string
+	var b3Headers map[string]string
 	for k, listVal := range internalMD {
 		if len(listVal.GetValues()) == 0 {
 			continue
 		}
+		if _, ok := trimmed[k]; ok {
+			continue
+		}
 
-		keyName := strings.ToLower(k)
+		keyName := CanonicalMetadataKey(k)
+		if b3Propagation && isB3Header(keyName) {
+			b3Headers = collectB3Header(b3Headers, keyName, listVal)
+			continue
+		}
 		// get both the trace headers for HTTP/GRPC and continue
 		switch keyName {
 		case diagConsts.TraceparentHeader:
@@ -272,15 +941,30 @@ func InternalMetadataToHTTPHeader(ctx context.Context, internalMD DaprInternalMe
 			tracestateValue = listVal.GetValues()[0]
 			continue
 		case diagConsts.GRPCTraceContextKey:
-			grpctracebinValue = listVal.GetValues()[0]
+			grpctracebinValues = listVal.GetValues()
 			continue
-		case DestinationIDHeader:
+		case DestinationIDHeader, DaprAPITokenHeader, MethodHeader:
 			continue
+		case AuthorizationHeader:
+			if !forwardAuthorizationHeader {
+				continue
+			}
 		case diagConsts.BaggageHeader:
 			setHeader(diagConsts.BaggageHeader, listVal.GetValues()[0])
 			continue
+		case ExpectHeader:
+			// Expect: 100-continue is consumed by Dapr, not forwarded.
+			if isExpectContinue(listVal.GetValues()[0]) {
+				continue
+			}
 		}
 
+		// Responses to HEAD requests have no body, so their content-length
+		// is the one declared by the app and is preserved.
+		if keyName == ContentLengthHeader && headResponse {
+			setHeader(ContentLengthHeader, listVal.GetValues()[0])
+			continue
+		}
 		if strings.HasSuffix(keyName, gRPCBinaryMetadataSuffix) || keyName == ContentTypeHeader || keyName == ContentLengthHeader {
 			continue
 		}
@@ -298,12 +982,55 @@ func InternalMetadataToHTTPHeader(ctx context.Context, internalMD DaprInternalMe
 			setHeader(ReservedGRPCMetadataToDaprPrefixHeader(keyName), v)
 		}
 	}
+	if isTracePropagationDisabled(ctx) {
+		return
+	}
+	traceHeaderSetter := setHeader
+	if b3Propagation {
+		traceHeaderSetter = func(key, value string) {
+			setHeader(key, value)
+			if key == diagConsts.TraceparentHeader {
+				setB3FromTraceparent(value, setHeader)
+			}
+		}
+	}
 	if IsGRPCProtocol(internalMD) {
 		// if grpcProtocol, then get grpc-trace-bin value, and attach it in HTTP traceparent and HTTP tracestate header
-		processGRPCToHTTPTraceHeaders(ctx, grpctracebinValue, setHeader)
+		processGRPCToHTTPTraceHeaders(ctx, grpctracebinValues, traceHeaderSetter)
 	} else {
-		processHTTPToHTTPTraceHeaders(ctx, traceparentValue, tracestateValue, setHeader)
+		if traceparentValue == "" {
+			traceparentValue = traceparentFromB3(b3Headers)
+		}
+		processHTTPToHTTPTraceHeaders(ctx, traceparentValue, tracestateValue, traceHeaderSetter)
+	}
+}
+
+// GrpcMetadataToHTTPHeader converts gRPC metadata to HTTP headers, applying the same rules as
+// InternalMetadataToHTTPHeader: reserved keys are prefixed, binary keys are dropped, and the
+// grpc-trace-bin value is converted to traceparent and tracestate headers.
+func GrpcMetadataToHTTPHeader(ctx context.Context, md metadata.MD, setHeader func(string, string)) {
+	InternalMetadataToHTTPHeader(ctx, internalv1pb.MetadataToInternalMetadata(md), setHeader)
+}
+
+// SetContentLength sets the content-length header to n, the length of a body that was transformed,
+// such as converted from Protobuf to JSON, after the metadata was converted to HTTP headers.
+// InternalMetadataToHTTPHeader drops the content-length of the original body, which is stale.
+// A negative n, for a body of unknown length, leaves the header unset.
+func SetContentLength(setHeader func(string, string), n int) {
+	if n < 0 {
+		return
+	}
+	setHeader(ContentLengthHeader, strconv.Itoa(n))
+}
+
+// ReasonPhraseFromCode returns the canonical name of a gRPC code, as defined by google.rpc.Code,
+// such as "NOT_FOUND" or "DEADLINE_EXCEEDED". Unknown codes return "UNKNOWN".
+func ReasonPhraseFromCode(code codes.Code) string {
+	//nolint:gosec
+	if name, ok := rpccode.Code_name[int32(code)]; ok {
+		return name
 	}
+	return rpccode.Code_UNKNOWN.String()
 }
 
 // HTTPStatusFromCode converts a gRPC error code into the corresponding HTTP response status.
@@ -351,12 +1078,40 @@ func HTTPStatusFromCode(code codes.Code) int {
 	return http.StatusInternalServerError
 }
 
+// HTTPStatusFromError converts a gRPC status error into the corresponding HTTP response status.
+// If the status carries the original HTTP status code in its ErrorInfo details, as set by
+// ErrorFromHTTPResponseCode and ErrorPayloadTooLarge, that code is returned.
+// Otherwise, the status code is converted with HTTPStatusFromCode.
+func HTTPStatusFromError(err error) int {
+	s := grpcStatus.Convert(err)
+	for _, detail := range s.Details() {
+		info, ok := detail.(*epb.ErrorInfo)
+		if !ok || info.GetDomain() != errorInfoDomain {
+			continue
+		}
+		if code, convErr := strconv.Atoi(info.GetMetadata()[errorInfoHTTPCodeMetadata]); convErr == nil && code > 0 {
+			return code
+		}
+	}
+	return HTTPStatusFromCode(s.Code())
+}
+
+// IsInformational returns true if the HTTP status code is an informational (1xx) status,
+// such as 100 Continue or 103 Early Hints, which is an interim response and not an error.
+func IsInformational(httpStatusCode int) bool {
+	return httpStatusCode >= 100 && httpStatusCode < 200
+}
+
 // CodeFromHTTPStatus converts http status code to gRPC status code
 // See: https://github.com/grpc/grpc/blob/master/doc/http-grpc-status-mapping.md
+// Informational statuses are interim responses and are mapped to OK rather than to an error.
 func CodeFromHTTPStatus(httpStatusCode int) codes.Code {
 	if httpStatusCode >= 200 && httpStatusCode < 300 {
 		return codes.OK
 	}
+	if IsInformational(httpStatusCode) {
+		return codes.OK
+	}
 
 	switch httpStatusCode {
 	case http.StatusRequestTimeout:
@@ -381,13 +1136,29 @@ func CodeFromHTTPStatus(httpStatusCode int) codes.Code {
 		return codes.Unimplemented
 	case http.StatusServiceUnavailable:
 		return codes.Unavailable
+	case http.StatusRequestEntityTooLarge:
+		return codes.ResourceExhausted
+	case http.StatusUnsupportedMediaType:
+		return codes.InvalidArgument
 	}
 
 	return codes.Unknown
 }
 
 // ErrorFromHTTPResponseCode converts http response code to gRPC status error.
+// The detail is truncated to the 63 characters allowed in ErrorInfo metadata values.
 func ErrorFromHTTPResponseCode(code int, detail string) error {
+	return ErrorFromHTTPResponseCodeWithLimit(code, detail, maxMetadataValueLen)
+}
+
+// ErrorFromHTTPResponseCodeWithLimit is like ErrorFromHTTPResponseCode, with the detail truncated to
+// maxDetailLen characters instead. This keeps longer details for consumers which are not bound by the
+// ErrorInfo limit. A non-positive maxDetailLen uses the ErrorInfo limit.
+func ErrorFromHTTPResponseCodeWithLimit(code int, detail string, maxDetailLen int) error {
+	if maxDetailLen <= 0 {
+		maxDetailLen = maxMetadataValueLen
+	}
+
 	grpcCode := CodeFromHTTPStatus(code)
 	if grpcCode == codes.OK {
 		return nil
@@ -395,9 +1166,9 @@ func ErrorFromHTTPResponseCode(code int, detail string) error {
 	httpStatusText := http.StatusText(code)
 	respStatus := grpcStatus.New(grpcCode, httpStatusText)
 
-	// Truncate detail string longer than 64 characters
-	if len(detail) >= maxMetadataValueLen {
-		detail = detail[:maxMetadataValueLen]
+	// Truncate detail string longer than the limit
+	if len(detail) > maxDetailLen {
+		detail = detail[:maxDetailLen]
 	}
 
 	resps, err := respStatus.WithDetails(
@@ -417,8 +1188,142 @@ func ErrorFromHTTPResponseCode(code int, detail string) error {
 	return resps.Err()
 }
 
+// ErrorFromHTTPResponse converts an HTTP response to a gRPC status error, like ErrorFromHTTPResponseCode,
+// with the beginning of the body as the detail if it is text, per its content-type. If the body is a
+// JSON-encoded google.rpc.Status, as decoded by StatusFromJSONBody, that status is returned instead.
+// It returns nil for successful responses. At most the length of the detail, or of a JSON status, is
+// read from the body, which is not closed.
+func ErrorFromHTTPResponse(resp *http.Response) error {
+	return ErrorFromHTTPResponseWithLimit(resp, maxMetadataValueLen)
+}
+
+// ErrorFromHTTPResponseWithLimit is like ErrorFromHTTPResponse, with at most maxDetailLen bytes of the
+// body used as the detail, as in ErrorFromHTTPResponseCodeWithLimit.
+func ErrorFromHTTPResponseWithLimit(resp *http.Response, maxDetailLen int) error {
+	if maxDetailLen <= 0 {
+		maxDetailLen = maxMetadataValueLen
+	}
+	if CodeFromHTTPStatus(resp.StatusCode) == codes.OK {
+		return nil
+	}
+
+	var detail string
+	contentType := resp.Header.Get(ContentTypeHeader)
+	switch {
+	case resp.Body == nil:
+	case IsJSONContentType(contentType):
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxStatusBodyLen))
+		if st, ok := StatusFromJSONBody(body); ok {
+			return grpcStatus.ErrorProto(st)
+		}
+		if len(body) > maxDetailLen {
+			body = body[:maxDetailLen]
+		}
+		detail = strings.TrimSpace(string(body))
+	case IsTextContentType(contentType):
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, int64(maxDetailLen)))
+		detail = strings.TrimSpace(string(snippet))
+	}
+	return ErrorFromHTTPResponseCodeWithLimit(resp.StatusCode, detail, maxDetailLen)
+}
+
+// StatusFromJSONBody decodes the body of an HTTP error response that is a JSON-encoded google.rpc.Status,
+// such as returned by apps following the Google API error model. It returns false if the body is not a
+// status, has unknown fields or detail types, or has the OK code.
+func StatusFromJSONBody(body []byte) (*spb.Status, bool) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || body[0] != '{' {
+		return nil, false
+	}
+	var st spb.Status
+	if err := protojson.Unmarshal(body, &st); err != nil {
+		return nil, false
+	}
+	if codes.Code(st.GetCode()) == codes.OK { //nolint:gosec
+		return nil, false
+	}
+	return &st, true
+}
+
+// ErrorPayloadTooLarge returns a ResourceExhausted gRPC status error for a payload of sizeBytes exceeding maxBytes.
+// The status carries the HTTP status code 413 in its ErrorInfo details, so HTTPStatusFromError maps it to
+// 413 Payload Too Large rather than 429 Too Many Requests.
+func ErrorPayloadTooLarge(sizeBytes, maxBytes int) error {
+	respStatus := grpcStatus.Newf(codes.ResourceExhausted, "payload size of %d bytes exceeds the maximum of %d bytes", sizeBytes, maxBytes)
+
+	resps, err := respStatus.WithDetails(
+		&epb.ErrorInfo{
+			Reason: http.StatusText(http.StatusRequestEntityTooLarge),
+			Domain: errorInfoDomain,
+			Metadata: map[string]string{
+				errorInfoHTTPCodeMetadata: strconv.Itoa(http.StatusRequestEntityTooLarge),
+			},
+		},
+	)
+	if err != nil {
+		resps = respStatus
+	}
+
+	return resps.Err()
+}
+
+// StatusForPartialStream returns the error of a streaming response that failed after sending the given
+// number of messages. If some messages were delivered, the error is annotated with an ErrorInfo detail
+// with the PARTIAL_STREAM reason and the number of messages sent, keeping its code, message and details,
+// so clients know the data was partially delivered. Otherwise, err is returned as is.
+func StatusForPartialStream(sent int, err error) error {
+	if err == nil || sent <= 0 {
+		return err
+	}
+
+	respStatus, ok := grpcStatus.FromError(err)
+	if !ok {
+		respStatus = grpcStatus.FromContextError(err)
+	}
+
+	resps, detailsErr := respStatus.WithDetails(
+		&epb.ErrorInfo{
+			Reason: errorInfoPartialStreamReason,
+			Domain: errorInfoDomain,
+			Metadata: map[string]string{
+				errorInfoSentMessagesMetadata: strconv.Itoa(sent),
+			},
+		},
+	)
+	if detailsErr != nil {
+		return err
+	}
+
+	return resps.Err()
+}
+
+// NewInternalStatus returns an internal status with the gRPC code, message and details.
+// Details that cannot be packed into an Any are omitted.
+func NewInternalStatus(code codes.Code, message string, details ...proto.Message) *internalv1pb.Status {
+	st := &internalv1pb.Status{
+		Code:    int32(code), //nolint:gosec
+		Message: message,
+	}
+	if len(details) > 0 {
+		st.Details = make([]*anypb.Any, 0, len(details))
+		for _, detail := range details {
+			a, err := anypb.New(detail)
+			if err != nil {
+				continue
+			}
+			st.Details = append(st.Details, a)
+		}
+	}
+	return st
+}
+
 // ErrorFromInternalStatus converts internal status to gRPC status error.
+// The details of the status are recorded in the error details metric, by type.
 func ErrorFromInternalStatus(internalStatus *internalv1pb.Status) error {
+	for _, detail := range internalStatus.GetDetails() {
+		diag.DefaultGRPCMonitoring.ErrorDetailRecorded(context.Background(), detail.GetTypeUrl())
+	}
+
 	respStatus := &spb.Status{
 		Code:    internalStatus.GetCode(),
 		Message: internalStatus.GetMessage(),
@@ -428,10 +1333,54 @@ func ErrorFromInternalStatus(internalStatus *internalv1pb.Status) error {
 	return grpcStatus.ErrorProto(respStatus)
 }
 
-func processGRPCToHTTPTraceHeaders(ctx context.Context, traceContext string, setHeader func(string, string)) {
+// BatchErrorsToStatuses converts the per-item errors of a batch operation to statuses, in the same
+// order, for bulk responses. A nil error is an OK status, and errors that are not gRPC status errors
+// have the Unknown code.
+func BatchErrorsToStatuses(errs []error) []*spb.Status {
+	statuses := make([]*spb.Status, len(errs))
+	for i, err := range errs {
+		statuses[i] = grpcStatus.Convert(err).Proto()
+	}
+	return statuses
+}
+
+// HTTPStatusFromInternalStatus returns the original HTTP status code and error message carried in the
+// ErrorInfo details of an internal status, as set by ErrorFromHTTPResponseCode. It returns false if the
+// status has no such details.
+func HTTPStatusFromInternalStatus(st *internalv1pb.Status) (code int, message string, ok bool) {
+	for _, detail := range st.GetDetails() {
+		var info epb.ErrorInfo
+		if !detail.MessageIs(&info) || detail.UnmarshalTo(&info) != nil || info.GetDomain() != errorInfoDomain {
+			continue
+		}
+		httpCode, err := strconv.Atoi(info.GetMetadata()[errorInfoHTTPCodeMetadata])
+		if err != nil || httpCode <= 0 {
+			continue
+		}
+		return httpCode, info.GetMetadata()[errorInfoHTTPErrorMetadata], true
+	}
+	return 0, "", false
+}
+
+// FirstValidTraceBin returns the span context of the first of the base64-encoded grpc-trace-bin values
+// that decodes to a valid span context, skipping the others. Clients should send a single value; this
+// makes the choice deterministic when misbehaving clients send several.
+func FirstValidTraceBin(values []string) (trace.SpanContext, bool) {
+	for _, v := range values {
+		decoded, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			continue
+		}
+		if sc, ok := diagUtils.SpanContextFromBinary(decoded); ok {
+			return sc, true
+		}
+	}
+	return trace.SpanContext{}, false
+}
+
+func processGRPCToHTTPTraceHeaders(ctx context.Context, grpctracebinValues []string, setHeader func(string, string)) {
 	// attach grpc-trace-bin value in traceparent and tracestate header
-	decoded, _ := base64.StdEncoding.DecodeString(traceContext)
-	sc, ok := diagUtils.SpanContextFromBinary(decoded)
+	sc, ok := FirstValidTraceBin(grpctracebinValues)
 	if !ok {
 		span := diagUtils.SpanFromContext(ctx)
 		sc = span.SpanContext()
@@ -440,6 +1389,13 @@ func processGRPCToHTTPTraceHeaders(ctx context.Context, traceContext string, set
 }
 
 func processHTTPToHTTPTraceHeaders(ctx context.Context, traceparentValue, traceStateValue string, setHeader func(string, string)) {
+	if strictTraceparent && traceparentValue != "" {
+		if err := diag.ValidateTraceparent(traceparentValue); err != nil {
+			log.Warnf("Dropping malformed traceparent header %q: %v", traceparentValue, err)
+			return
+		}
+	}
+
 	if traceparentValue == "" {
 		span := diagUtils.SpanFromContext(ctx)
 		diag.SpanContextToHTTPHeaders(span.SpanContext(), setHeader)
@@ -470,36 +1426,42 @@ func processHTTPToGRPCTraceHeader(ctx context.Context, md metadata.MD, tracepare
 	md.Set(diagConsts.GRPCTraceContextKey, string(diagUtils.BinaryFromSpanContext(sc)))
 }
 
-func processGRPCToGRPCTraceHeader(ctx context.Context, md metadata.MD, grpctracebinValue string) {
-	if grpctracebinValue == "" {
-		span := diagUtils.SpanFromContext(ctx)
-		sc := span.SpanContext()
-
-		// Workaround for lack of grpc-trace-bin support in OpenTelemetry (unlike OpenCensus), tracking issue https://github.com/open-telemetry/opentelemetry-specification/issues/639
-		// grpc-dotnet client adheres to OpenTelemetry Spec which only supports http based traceparent header in gRPC path
-		// TODO : Remove this workaround fix once grpc-dotnet supports grpc-trace-bin header. Tracking issue https://github.com/dapr/dapr/issues/1827
-		diag.SpanContextToHTTPHeaders(sc, func(header, value string) {
-			md.Set(header, value)
-		})
-		md.Set(diagConsts.GRPCTraceContextKey, string(diagUtils.BinaryFromSpanContext(sc)))
-	} else {
-		decoded, err := base64.StdEncoding.DecodeString(grpctracebinValue)
-		if err == nil {
-			// Workaround for lack of grpc-trace-bin support in OpenTelemetry (unlike OpenCensus), tracking issue https://github.com/open-telemetry/opentelemetry-specification/issues/639
-			// grpc-dotnet client adheres to OpenTelemetry Spec which only supports http based traceparent header in gRPC path
-			// TODO : Remove this workaround fix once grpc-dotnet supports grpc-trace-bin header. Tracking issue https://github.com/dapr/dapr/issues/1827
-			if sc, ok := diagUtils.SpanContextFromBinary(decoded); ok {
-				diag.SpanContextToHTTPHeaders(sc, func(header, value string) {
-					md.Set(header, value)
-				})
+// processGRPCToGRPCTraceHeader propagates the first valid of the grpc-trace-bin values, as selected by
+// FirstValidTraceBin. If none is valid, the first value is passed through as is, and if there are none,
+// the span context in ctx is propagated.
+func processGRPCToGRPCTraceHeader(ctx context.Context, md metadata.MD, grpctracebinValues []string) {
+	sc, ok := FirstValidTraceBin(grpctracebinValues)
+	if !ok {
+		if len(grpctracebinValues) > 0 && grpctracebinValues[0] != "" {
+			if decoded, err := base64.StdEncoding.DecodeString(grpctracebinValues[0]); err == nil {
+				md.Set(diagConsts.GRPCTraceContextKey, string(decoded))
 			}
-			md.Set(diagConsts.GRPCTraceContextKey, string(decoded))
+			return
 		}
+		sc = diagUtils.SpanFromContext(ctx).SpanContext()
 	}
+
+	// Workaround for lack of grpc-trace-bin support in OpenTelemetry (unlike OpenCensus), tracking issue https://github.com/open-telemetry/opentelemetry-specification/issues/639
+	// grpc-dotnet client adheres to OpenTelemetry Spec which only supports http based traceparent header in gRPC path
+	// TODO : Remove this workaround fix once grpc-dotnet supports grpc-trace-bin header. Tracking issue https://github.com/dapr/dapr/issues/1827
+	diag.SpanContextToHTTPHeaders(sc, func(header, value string) {
+		md.Set(header, value)
+	})
+	md.Set(diagConsts.GRPCTraceContextKey, string(diagUtils.BinaryFromSpanContext(sc)))
 }
 
 // ProtobufToJSON serializes Protobuf message to json format.
 func ProtobufToJSON(message protoreflect.ProtoMessage) ([]byte, error) {
+	return ProtobufToJSONContext(context.Background(), message)
+}
+
+// ProtobufToJSONContext serializes Protobuf message to json format.
+// The time spent is recorded if serialization metrics are enabled, and added to the span in ctx.
+func ProtobufToJSONContext(ctx context.Context, message protoreflect.ProtoMessage) ([]byte, error) {
+	if diag.DefaultGRPCMonitoring.SerializationMetricsEnabled() {
+		defer diag.DefaultGRPCMonitoring.SerializationCompleted(ctx, "marshal", time.Now())
+	}
+
 	marshaler := protojson.MarshalOptions{
 		Indent:          "",
 		UseProtoNames:   false,
@@ -508,18 +1470,30 @@ func ProtobufToJSON(message protoreflect.ProtoMessage) ([]byte, error) {
 	return marshaler.Marshal(message)
 }
 
+// JSONToProtobuf deserializes a json document into the Protobuf message.
+// The time spent is recorded if serialization metrics are enabled, and added to the span in ctx.
+func JSONToProtobuf(ctx context.Context, data []byte, message protoreflect.ProtoMessage) error {
+	if diag.DefaultGRPCMonitoring.SerializationMetricsEnabled() {
+		defer diag.DefaultGRPCMonitoring.SerializationCompleted(ctx, "unmarshal", time.Now())
+	}
+
+	unmarshaler := protojson.UnmarshalOptions{
+		DiscardUnknown: true,
+	}
+	return unmarshaler.Unmarshal(data, message)
+}
+
 // WithCustomGRPCMetadata applies a metadata map to the outgoing context metadata.
 func WithCustomGRPCMetadata(ctx context.Context, md map[string]string) context.Context {
 	for k, v := range md {
-		if strings.EqualFold(k, ContentTypeHeader) ||
-			strings.EqualFold(k, ContentLengthHeader) {
+		key := CanonicalMetadataKey(k)
+		if key == ContentTypeHeader || key == ContentLengthHeader {
 			// There is no use of the original payload's content-length because
 			// the entire data is already in the cloud event.
 			continue
 		}
 
-		// Uppercase keys will be converted to lowercase.
-		ctx = metadata.AppendToOutgoingContext(ctx, k, v)
+		ctx = metadata.AppendToOutgoingContext(ctx, key, v)
 	}
 
 	return ctx
@@ -14,20 +14,42 @@ limitations under the License.
 package v1
 
 import (
+	"bytes"
+	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 	epb "google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
+	diag "github.com/dapr/dapr/pkg/diagnostics"
+	diagConsts "github.com/dapr/dapr/pkg/diagnostics/consts"
+	diagUtils "github.com/dapr/dapr/pkg/diagnostics/utils"
 	internalv1pb "github.com/dapr/dapr/pkg/proto/internals/v1"
+	"github.com/dapr/dapr/pkg/security/spiffe"
 )
 
 func TestInternalMetadataToHTTPHeader(t *testing.T) {
@@ -60,6 +82,75 @@ func TestInternalMetadataToHTTPHeader(t *testing.T) {
 	assert.Equal(t, expectedKeyNames, savedHeaderKeyNames)
 }
 
+func TestGrpcMetadataToHTTPHeader(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{75, 249, 47, 53, 119, 179, 77, 166, 163, 206, 146, 157, 14, 14, 71, 54},
+		SpanID:     trace.SpanID{0, 240, 103, 170, 11, 169, 2, 183},
+		TraceFlags: trace.TraceFlags(1),
+	})
+	md := metadata.Pairs(
+		"custom-header", "value",
+		":path", "/myapp/mymethod",
+		"custom-bin", "\x00\x01binary",
+		"content-type", GRPCContentType,
+		diagConsts.GRPCTraceContextKey, string(diagUtils.BinaryFromSpanContext(sc)),
+	)
+
+	headers := map[string][]string{}
+	GrpcMetadataToHTTPHeader(t.Context(), md, func(k, v string) {
+		headers[k] = append(headers[k], v)
+	})
+
+	assert.Equal(t, map[string][]string{
+		"custom-header":              {"value"},
+		"dapr-path":                  {"/myapp/mymethod"},
+		diagConsts.TraceparentHeader: {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+	}, headers)
+}
+
+func TestNewListStringValue(t *testing.T) {
+	assert.Equal(t, []string{"a", "b", "c"}, NewListStringValue("a", "b", "c").GetValues())
+	assert.Empty(t, NewListStringValue().GetValues())
+	assert.Equal(t, []string{"v"}, SingleValue("v").GetValues())
+
+	md := DaprInternalMetadata{
+		"multi":  NewListStringValue("value1", "value2"),
+		"single": SingleValue("value"),
+	}
+	convertedMD := InternalMetadataToGrpcMetadata(t.Context(), md, false)
+	assert.Equal(t, []string{"value1", "value2"}, convertedMD["multi"])
+	assert.Equal(t, []string{"value"}, convertedMD["single"])
+}
+
+func TestSetDaprHeaderPrefix(t *testing.T) {
+	t.Cleanup(func() {
+		require.NoError(t, SetDaprHeaderPrefix(DaprHeaderPrefix))
+	})
+
+	t.Run("invalid prefix", func(t *testing.T) {
+		require.Error(t, SetDaprHeaderPrefix(""))
+		require.Error(t, SetDaprHeaderPrefix("-"))
+		require.Error(t, SetDaprHeaderPrefix("myco"))
+		assert.Equal(t, DaprHeaderPrefix, daprHeaderPrefix)
+	})
+
+	t.Run("custom prefix", func(t *testing.T) {
+		require.NoError(t, SetDaprHeaderPrefix("myco-"))
+
+		assert.Equal(t, "myco-method", ReservedGRPCMetadataToDaprPrefixHeader(":method"))
+		assert.Equal(t, "myco-grpc-timeout", ReservedGRPCMetadataToDaprPrefixHeader("grpc-timeout"))
+		assert.Equal(t, "custom-header", ReservedGRPCMetadataToDaprPrefixHeader("custom-header"))
+
+		md := InternalMetadataToGrpcMetadata(t.Context(), map[string]*internalv1pb.ListStringValue{
+			"Host":       {Values: []string{"localhost"}},
+			"User-Agent": {Values: []string{"Go-http-client/1.1"}},
+		}, true)
+		assert.Equal(t, []string{"localhost"}, md["myco-host"])
+		assert.Empty(t, md["dapr-host"])
+		assert.Equal(t, []string{"Go-http-client/1.1"}, md["user-agent"])
+	})
+}
+
 func TestIsHopByHopHeader(t *testing.T) {
 	hopByHopHeaders := []string{
 		"Connection", "connection",
@@ -141,6 +232,131 @@ func TestInternalMetadataToHTTPHeaderStripsConnectionNominatedMixedCase(t *testi
 	assert.Equal(t, []string{"x-custom-header"}, savedHeaderKeyNames)
 }
 
+func TestWantsContinue(t *testing.T) {
+	assert.True(t, WantsContinue(DaprInternalMetadata{
+		"Expect": {Values: []string{"100-continue"}},
+	}))
+	assert.True(t, WantsContinue(DaprInternalMetadata{
+		"expect": {Values: []string{"100-Continue"}},
+	}))
+	assert.False(t, WantsContinue(DaprInternalMetadata{
+		"Expect": {Values: []string{"something-else"}},
+	}))
+	assert.False(t, WantsContinue(DaprInternalMetadata{
+		"Host": {Values: []string{"localhost"}},
+	}))
+}
+
+func TestAcceptsEncoding(t *testing.T) {
+	t.Run("gzip accepted", func(t *testing.T) {
+		md := DaprInternalMetadata{"grpc-accept-encoding": SingleValue("identity,deflate,gzip")}
+		assert.True(t, AcceptsEncoding(md, "gzip"))
+		assert.False(t, AcceptsEncoding(md, "br"))
+
+		md = DaprInternalMetadata{"Accept-Encoding": SingleValue("br;q=1.0, GZIP;q=0.5")}
+		assert.True(t, AcceptsEncoding(md, "gzip"))
+	})
+
+	t.Run("gzip forbidden", func(t *testing.T) {
+		md := DaprInternalMetadata{"accept-encoding": SingleValue("gzip;q=0, *")}
+		assert.False(t, AcceptsEncoding(md, "gzip"))
+		assert.True(t, AcceptsEncoding(md, "br"))
+	})
+
+	t.Run("wildcard", func(t *testing.T) {
+		md := DaprInternalMetadata{"accept-encoding": SingleValue("*")}
+		assert.True(t, AcceptsEncoding(md, "gzip"))
+
+		md = DaprInternalMetadata{"accept-encoding": SingleValue("br, *;q=0")}
+		assert.False(t, AcceptsEncoding(md, "gzip"))
+		assert.False(t, AcceptsEncoding(md, "identity"))
+		assert.True(t, AcceptsEncoding(md, "br"))
+	})
+
+	t.Run("no header", func(t *testing.T) {
+		assert.False(t, AcceptsEncoding(DaprInternalMetadata{}, "gzip"))
+		assert.True(t, AcceptsEncoding(DaprInternalMetadata{}, "identity"))
+	})
+}
+
+func TestClientIPFromMetadata(t *testing.T) {
+	t.Run("multi-hop x-forwarded-for", func(t *testing.T) {
+		assert.Equal(t, "203.0.113.195", ClientIPFromMetadata(DaprInternalMetadata{
+			"X-Forwarded-For": NewListStringValue("203.0.113.195, 70.41.3.18, 150.172.238.178", "10.0.0.1"),
+			"X-Real-Ip":       SingleValue("150.172.238.178"),
+		}))
+	})
+
+	t.Run("single x-forwarded-for", func(t *testing.T) {
+		assert.Equal(t, "2001:db8::1", ClientIPFromMetadata(DaprInternalMetadata{
+			"x-forwarded-for": SingleValue("2001:db8::1"),
+		}))
+	})
+
+	t.Run("x-real-ip", func(t *testing.T) {
+		assert.Equal(t, "198.51.100.7", ClientIPFromMetadata(DaprInternalMetadata{
+			"x-real-ip": SingleValue("198.51.100.7"),
+		}))
+	})
+
+	t.Run("none", func(t *testing.T) {
+		assert.Empty(t, ClientIPFromMetadata(DaprInternalMetadata{
+			"Host": SingleValue("localhost"),
+		}))
+	})
+}
+
+func TestPreferDirectives(t *testing.T) {
+	t.Run("multiple directives with and without values", func(t *testing.T) {
+		directives := PreferDirectives(DaprInternalMetadata{
+			"Prefer": NewListStringValue(`respond-async, return=minimal`, `wait=10; foo="bar", handling="lenient"`),
+		})
+		assert.Equal(t, map[string]string{
+			"respond-async": "",
+			"return":        "minimal",
+			"wait":          "10",
+			"handling":      "lenient",
+		}, directives)
+	})
+
+	t.Run("first occurrence takes precedence", func(t *testing.T) {
+		directives := PreferDirectives(DaprInternalMetadata{
+			"prefer": SingleValue("Return=minimal, return=representation"),
+		})
+		assert.Equal(t, map[string]string{"return": "minimal"}, directives)
+	})
+
+	t.Run("no prefer header", func(t *testing.T) {
+		assert.Empty(t, PreferDirectives(DaprInternalMetadata{
+			"Host": SingleValue("localhost"),
+		}))
+	})
+}
+
+func TestExpectContinueNotForwarded(t *testing.T) {
+	md := DaprInternalMetadata{
+		"Expect":     {Values: []string{"100-continue"}},
+		"User-Agent": {Values: []string{"Go-http-client/1.1"}},
+	}
+
+	t.Run("gRPC metadata", func(t *testing.T) {
+		convertedMD := InternalMetadataToGrpcMetadata(t.Context(), md, true)
+		assert.Empty(t, convertedMD["dapr-expect"])
+		assert.Empty(t, convertedMD["expect"])
+		assert.Equal(t, []string{"Go-http-client/1.1"}, convertedMD["user-agent"])
+	})
+
+	t.Run("HTTP headers", func(t *testing.T) {
+		headers := map[string]string{}
+		InternalMetadataToHTTPHeader(t.Context(), md, func(k, v string) {
+			headers[k] = v
+		})
+		assert.NotContains(t, headers, "expect")
+		assert.NotContains(t, headers, "dapr-expect")
+		assert.Equal(t, "Go-http-client/1.1", headers["user-agent"])
+	})
+}
+
 func TestIsJSONContentType(t *testing.T) {
 	contentTypeTests := []struct {
 		in  string
@@ -158,6 +374,192 @@ func TestIsJSONContentType(t *testing.T) {
 	}
 }
 
+func TestIsTextContentType(t *testing.T) {
+	contentTypeTests := []struct {
+		in  string
+		out bool
+	}{
+		{JSONContentType, true},
+		{"application/json; charset=utf-8", true},
+		{"application/cloudevents+json", true},
+		{"application/xml", true},
+		{"application/atom+xml", true},
+		{"application/x-www-form-urlencoded", true},
+		{"text/plain", true},
+		{"text/html; charset=utf-8", true},
+		{"Text/CSV", true},
+		{ProtobufContentType, false},
+		{OctetStreamContentType, false},
+		{GRPCContentType, false},
+		{"image/png", false},
+		{"", false},
+	}
+
+	for _, tt := range contentTypeTests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.out, IsTextContentType(tt.in))
+		})
+	}
+}
+
+func TestHasTraceContext(t *testing.T) {
+	t.Run("traceparent", func(t *testing.T) {
+		assert.True(t, HasTraceContext(DaprInternalMetadata{
+			"traceparent": {Values: []string{"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}},
+		}))
+		assert.True(t, HasTraceContext(DaprInternalMetadata{
+			"Traceparent": {Values: []string{"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}},
+		}))
+	})
+
+	t.Run("grpc-trace-bin", func(t *testing.T) {
+		assert.True(t, HasTraceContext(DaprInternalMetadata{
+			"grpc-trace-bin": {Values: []string{"not-decoded"}},
+		}))
+	})
+
+	t.Run("neither", func(t *testing.T) {
+		assert.False(t, HasTraceContext(DaprInternalMetadata{
+			"tracestate": {Values: []string{"foo=bar"}},
+		}))
+		assert.False(t, HasTraceContext(nil))
+	})
+}
+
+func TestB3Propagation(t *testing.T) {
+	SetB3Propagation(true)
+	t.Cleanup(func() {
+		SetB3Propagation(false)
+	})
+
+	const (
+		traceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID      = "00f067aa0ba902b7"
+		traceparent = "00-" + traceID + "-" + spanID + "-01"
+	)
+
+	t.Run("single header to gRPC metadata", func(t *testing.T) {
+		md := InternalMetadataToGrpcMetadata(t.Context(), DaprInternalMetadata{
+			"b3": SingleValue(traceID + "-" + spanID + "-1"),
+		}, true)
+		assert.Equal(t, []string{traceparent}, md["traceparent"])
+		assert.Empty(t, md["b3"])
+		assert.Equal(t, []string{traceID}, md["x-b3-traceid"])
+		assert.Equal(t, []string{spanID}, md["x-b3-spanid"])
+		assert.Equal(t, []string{"1"}, md["x-b3-sampled"])
+	})
+
+	t.Run("multi header to HTTP headers", func(t *testing.T) {
+		headers := map[string]string{}
+		InternalMetadataToHTTPHeader(t.Context(), DaprInternalMetadata{
+			"X-B3-TraceId": SingleValue(traceID),
+			"X-B3-SpanId":  SingleValue(spanID),
+			"X-B3-Sampled": SingleValue("1"),
+		}, func(k, v string) {
+			headers[k] = v
+		})
+		assert.Equal(t, traceparent, headers["traceparent"])
+		assert.Equal(t, traceID, headers["x-b3-traceid"])
+		assert.Equal(t, spanID, headers["x-b3-spanid"])
+		assert.Equal(t, "1", headers["x-b3-sampled"])
+	})
+
+	t.Run("traceparent takes precedence", func(t *testing.T) {
+		const otherTraceparent = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+		md := InternalMetadataToGrpcMetadata(t.Context(), DaprInternalMetadata{
+			"traceparent": SingleValue(otherTraceparent),
+			"b3":          SingleValue(traceID + "-" + spanID + "-1"),
+		}, true)
+		assert.Equal(t, []string{otherTraceparent}, md["traceparent"])
+		assert.Equal(t, []string{"0af7651916cd43dd8448eb211c80319c"}, md["x-b3-traceid"])
+	})
+}
+
+func TestSniffContentType(t *testing.T) {
+	pbBody, err := proto.Marshal(&internalv1pb.ListStringValue{Values: []string{"a", "b"}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		body []byte
+		want string
+	}{
+		{"JSON object", []byte(`{"key":"value"}`), JSONContentType},
+		{"JSON array", []byte(`[1, 2, 3]`), JSONContentType},
+		{"JSON with leading whitespace", []byte(" \n\t{\"key\":1}"), JSONContentType},
+		{"protobuf", pbBody, ProtobufContentType},
+		{"binary", []byte{0x00, 0xff, 0xfe, 0x07}, OctetStreamContentType},
+		{"plain text", []byte("hello world"), "text/plain; charset=utf-8"},
+		{"empty", nil, OctetStreamContentType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SniffContentType(tt.body))
+		})
+	}
+}
+
+func TestHeadResponse(t *testing.T) {
+	headMD := DaprInternalMetadata{
+		MethodHeader:        SingleValue("HEAD"),
+		"Content-Type":      SingleValue(JSONContentType),
+		ContentLengthHeader: SingleValue("42"),
+		"custom-header":     SingleValue("value"),
+	}
+
+	t.Run("HEAD response is detected", func(t *testing.T) {
+		assert.True(t, IsHeadResponse(headMD))
+		assert.True(t, IsHeadResponse(DaprInternalMetadata{"Dapr-Method": SingleValue("head")}))
+		assert.False(t, IsHeadResponse(DaprInternalMetadata{MethodHeader: SingleValue("GET")}))
+		assert.False(t, IsHeadResponse(DaprInternalMetadata{}))
+	})
+
+	t.Run("marker is removed", func(t *testing.T) {
+		md := DaprInternalMetadata{
+			"Dapr-Method":   SingleValue("HEAD"),
+			MethodHeader:    SingleValue("HEAD"),
+			"custom-header": SingleValue("value"),
+		}
+		RemoveMethodHeader(md)
+		assert.False(t, IsHeadResponse(md))
+		assert.Equal(t, DaprInternalMetadata{"custom-header": SingleValue("value")}, md)
+	})
+
+	t.Run("content type is trusted with an empty body", func(t *testing.T) {
+		assert.Equal(t, JSONContentType, ResolveContentType(headMD, nil))
+		assert.Empty(t, ResolveContentType(DaprInternalMetadata{MethodHeader: SingleValue("HEAD")}, nil))
+	})
+
+	t.Run("content type is sniffed without header", func(t *testing.T) {
+		assert.Equal(t, JSONContentType, ResolveContentType(DaprInternalMetadata{}, []byte(`{"a":1}`)))
+		assert.Equal(t, OctetStreamContentType, ResolveContentType(DaprInternalMetadata{}, nil))
+	})
+
+	t.Run("content length is preserved", func(t *testing.T) {
+		headers := map[string]string{}
+		InternalMetadataToHTTPHeader(t.Context(), headMD, func(k, v string) {
+			headers[k] = v
+		})
+		assert.Equal(t, "42", headers[ContentLengthHeader])
+		assert.Equal(t, "value", headers["custom-header"])
+		assert.NotContains(t, headers, ContentTypeHeader)
+		assert.NotContains(t, headers, MethodHeader)
+
+		grpcMD := InternalMetadataToGrpcMetadata(t.Context(), headMD, false)
+		assert.Empty(t, grpcMD.Get(MethodHeader))
+	})
+
+	t.Run("content length is not forwarded for other responses", func(t *testing.T) {
+		headers := map[string]string{}
+		InternalMetadataToHTTPHeader(t.Context(), DaprInternalMetadata{
+			ContentLengthHeader: SingleValue("42"),
+		}, func(k, v string) {
+			headers[k] = v
+		})
+		assert.NotContains(t, headers, ContentLengthHeader)
+	})
+}
+
 func TestInternalMetadataToGrpcMetadata(t *testing.T) {
 	httpHeaders := map[string]*internalv1pb.ListStringValue{
 		"Host": {
@@ -197,7 +599,7 @@ func TestInternalMetadataToGrpcMetadata(t *testing.T) {
 	t.Run("without http header conversion for http headers", func(t *testing.T) {
 		convertedMD := InternalMetadataToGrpcMetadata(ctx, httpHeaders, false)
 		// always trace header is returned
-		assert.Equal(t, 11, convertedMD.Len())
+		assert.Equal(t, 10, convertedMD.Len())
 
 		testHeaders := []struct {
 			key      string
@@ -209,7 +611,6 @@ func TestInternalMetadataToGrpcMetadata(t *testing.T) {
 			{"content-type", "application/json"},
 			{"keep-alive", "timeout=5"},
 			{"proxy-connection", "keep-alive"},
-			{"transfer-encoding", "gzip"},
 			{"upgrade", "WebSocket"},
 			{"accept-encoding", "gzip, deflate"},
 			{"user-agent", "Go-http-client/1.1"},
@@ -223,7 +624,7 @@ func TestInternalMetadataToGrpcMetadata(t *testing.T) {
 	t.Run("with http header conversion for http headers", func(t *testing.T) {
 		convertedMD := InternalMetadataToGrpcMetadata(ctx, httpHeaders, true)
 		// always trace header is returned
-		assert.Equal(t, 11, convertedMD.Len())
+		assert.Equal(t, 10, convertedMD.Len())
 
 		testHeaders := []struct {
 			key      string
@@ -235,7 +636,6 @@ func TestInternalMetadataToGrpcMetadata(t *testing.T) {
 			{"dapr-content-type", "application/json"},
 			{"dapr-keep-alive", "timeout=5"},
 			{"dapr-proxy-connection", "keep-alive"},
-			{"dapr-transfer-encoding", "gzip"},
 			{"dapr-upgrade", "WebSocket"},
 			{"accept-encoding", "gzip, deflate"},
 			{"user-agent", "Go-http-client/1.1"},
@@ -244,6 +644,8 @@ func TestInternalMetadataToGrpcMetadata(t *testing.T) {
 		for _, ht := range testHeaders {
 			assert.Equal(t, ht.expected, convertedMD[ht.key][0])
 		}
+		assert.NotContains(t, convertedMD, "dapr-transfer-encoding")
+		assert.NotContains(t, convertedMD, "transfer-encoding")
 	})
 
 	keyBinValue := []byte{100, 50}
@@ -297,6 +699,115 @@ func TestInternalMetadataToGrpcMetadata(t *testing.T) {
 	})
 }
 
+func TestErrorFromHTTPResponse(t *testing.T) {
+	newResponse := func(code int, contentType, body string) *http.Response {
+		header := http.Header{}
+		if contentType != "" {
+			header.Set("Content-Type", contentType)
+		}
+		return &http.Response{
+			StatusCode: code,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		require.NoError(t, ErrorFromHTTPResponse(newResponse(http.StatusOK, JSONContentType, `{}`)))
+	})
+
+	t.Run("NotFound with a JSON body", func(t *testing.T) {
+		err := ErrorFromHTTPResponse(newResponse(http.StatusNotFound, "application/json; charset=utf-8", `{"error":"order 42 not found"}`))
+
+		s, ok := status.FromError(err)
+		require.True(t, ok)
+		assert.Equal(t, codes.NotFound, s.Code())
+		assert.Equal(t, "Not Found", s.Message())
+		errInfo := (s.Details()[0]).(*epb.ErrorInfo)
+		assert.Equal(t, "404", errInfo.GetMetadata()[errorInfoHTTPCodeMetadata])
+		assert.JSONEq(t, `{"error":"order 42 not found"}`, errInfo.GetMetadata()[errorInfoHTTPErrorMetadata])
+	})
+
+	t.Run("long body is truncated", func(t *testing.T) {
+		err := ErrorFromHTTPResponse(newResponse(http.StatusInternalServerError, "text/plain", strings.Repeat("test", 100)))
+
+		s, _ := status.FromError(err)
+		errInfo := (s.Details()[0]).(*epb.ErrorInfo)
+		assert.Len(t, errInfo.GetMetadata()[errorInfoHTTPErrorMetadata], maxMetadataValueLen)
+	})
+
+	t.Run("long body is truncated to a custom length", func(t *testing.T) {
+		err := ErrorFromHTTPResponseWithLimit(newResponse(http.StatusInternalServerError, "text/plain", strings.Repeat("test", 100)), 256)
+
+		s, _ := status.FromError(err)
+		errInfo := (s.Details()[0]).(*epb.ErrorInfo)
+		assert.Len(t, errInfo.GetMetadata()[errorInfoHTTPErrorMetadata], 256)
+	})
+
+	t.Run("binary body is not used as detail", func(t *testing.T) {
+		err := ErrorFromHTTPResponse(newResponse(http.StatusBadRequest, OctetStreamContentType, "\x00\x01\x02"))
+
+		s, _ := status.FromError(err)
+		errInfo := (s.Details()[0]).(*epb.ErrorInfo)
+		assert.Equal(t, "400", errInfo.GetMetadata()[errorInfoHTTPCodeMetadata])
+		assert.Empty(t, errInfo.GetMetadata()[errorInfoHTTPErrorMetadata])
+	})
+
+	t.Run("JSON status body is preferred", func(t *testing.T) {
+		err := ErrorFromHTTPResponse(newResponse(http.StatusBadRequest, JSONContentType, jsonStatusBody))
+
+		s, ok := status.FromError(err)
+		require.True(t, ok)
+		assert.Equal(t, codes.NotFound, s.Code())
+		assert.Equal(t, "order 42 not found", s.Message())
+		require.Len(t, s.Details(), 1)
+		errInfo := (s.Details()[0]).(*epb.ErrorInfo)
+		assert.Equal(t, "orders.example.com", errInfo.GetDomain())
+	})
+}
+
+// jsonStatusBody is a JSON-encoded google.rpc.Status with an ErrorInfo detail.
+const jsonStatusBody = `{
+	"code": 5,
+	"message": "order 42 not found",
+	"details": [{
+		"@type": "type.googleapis.com/google.rpc.ErrorInfo",
+		"reason": "ORDER_NOT_FOUND",
+		"domain": "orders.example.com",
+		"metadata": {"order_id": "42"}
+	}]
+}`
+
+func TestStatusFromJSONBody(t *testing.T) {
+	t.Run("status with details", func(t *testing.T) {
+		st, ok := StatusFromJSONBody([]byte(jsonStatusBody))
+		require.True(t, ok)
+		assert.Equal(t, int32(codes.NotFound), st.GetCode())
+		assert.Equal(t, "order 42 not found", st.GetMessage())
+		require.Len(t, st.GetDetails(), 1)
+
+		var errInfo epb.ErrorInfo
+		require.NoError(t, st.GetDetails()[0].UnmarshalTo(&errInfo))
+		assert.Equal(t, "ORDER_NOT_FOUND", errInfo.GetReason())
+		assert.Equal(t, "orders.example.com", errInfo.GetDomain())
+		assert.Equal(t, map[string]string{"order_id": "42"}, errInfo.GetMetadata())
+	})
+
+	t.Run("not a status", func(t *testing.T) {
+		for _, body := range []string{
+			"",
+			"not found",
+			`["a", "b"]`,
+			`{"error":"order 42 not found"}`,
+			`{"message":"no code"}`,
+			`{"code":5,"details":[{"@type":"type.googleapis.com/unknown.Type"}]}`,
+		} {
+			_, ok := StatusFromJSONBody([]byte(body))
+			assert.False(t, ok, body)
+		}
+	})
+}
+
 func TestErrorFromHTTPResponseCode(t *testing.T) {
 	t.Run("OK", func(t *testing.T) {
 		// act
@@ -353,6 +864,85 @@ func TestErrorFromHTTPResponseCode(t *testing.T) {
 		errInfo := (s.Details()[0]).(*epb.ErrorInfo)
 		assert.Len(t, errInfo.GetMetadata()[errorInfoHTTPErrorMetadata], 63)
 	})
+
+	t.Run("Custom detail length", func(t *testing.T) {
+		longMessage := strings.Repeat("test", 100)
+
+		// act
+		err := ErrorFromHTTPResponseCodeWithLimit(500, longMessage, 256)
+
+		// assert
+		s, _ := status.FromError(err)
+		errInfo := (s.Details()[0]).(*epb.ErrorInfo)
+		assert.Equal(t, longMessage[:256], errInfo.GetMetadata()[errorInfoHTTPErrorMetadata])
+
+		// A detail shorter than the limit is kept as is.
+		err = ErrorFromHTTPResponseCodeWithLimit(500, "short", 256)
+		s, _ = status.FromError(err)
+		errInfo = (s.Details()[0]).(*epb.ErrorInfo)
+		assert.Equal(t, "short", errInfo.GetMetadata()[errorInfoHTTPErrorMetadata])
+	})
+}
+
+func TestInformationalStatus(t *testing.T) {
+	for _, code := range []int{http.StatusContinue, http.StatusEarlyHints, 199} {
+		t.Run(strconv.Itoa(code), func(t *testing.T) {
+			assert.True(t, IsInformational(code))
+			assert.Equal(t, codes.OK, CodeFromHTTPStatus(code))
+			require.NoError(t, ErrorFromHTTPResponseCode(code, ""))
+		})
+	}
+
+	for _, code := range []int{0, 99, http.StatusOK, http.StatusNotFound} {
+		assert.False(t, IsInformational(code), code)
+	}
+}
+
+func TestPayloadTooLargeAndUnsupportedMediaTypeMapping(t *testing.T) {
+	tests := []struct {
+		httpStatus int
+		code       codes.Code
+	}{
+		{http.StatusRequestEntityTooLarge, codes.ResourceExhausted},
+		{http.StatusUnsupportedMediaType, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.httpStatus), func(t *testing.T) {
+			assert.Equal(t, tt.code, CodeFromHTTPStatus(tt.httpStatus))
+
+			// The original HTTP status is preserved across the gRPC status error.
+			err := ErrorFromHTTPResponseCode(tt.httpStatus, "detail")
+			assert.Equal(t, tt.code, status.Code(err))
+			assert.Equal(t, tt.httpStatus, HTTPStatusFromError(err))
+		})
+	}
+}
+
+func TestErrorPayloadTooLarge(t *testing.T) {
+	err := ErrorPayloadTooLarge(8<<20, 4<<20)
+	require.Error(t, err)
+
+	s, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.ResourceExhausted, s.Code())
+	assert.Equal(t, "payload size of 8388608 bytes exceeds the maximum of 4194304 bytes", s.Message())
+
+	require.Len(t, s.Details(), 1)
+	errInfo, ok := s.Details()[0].(*epb.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, "Request Entity Too Large", errInfo.GetReason())
+	assert.Equal(t, "413", errInfo.GetMetadata()[errorInfoHTTPCodeMetadata])
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, HTTPStatusFromError(err))
+	// Without the marker, ResourceExhausted maps to 429.
+	assert.Equal(t, http.StatusTooManyRequests, HTTPStatusFromError(status.Error(codes.ResourceExhausted, "quota")))
+}
+
+func TestHTTPStatusFromError(t *testing.T) {
+	assert.Equal(t, http.StatusNotFound, HTTPStatusFromError(ErrorFromHTTPResponseCode(http.StatusNotFound, "missing")))
+	assert.Equal(t, http.StatusServiceUnavailable, HTTPStatusFromError(status.Error(codes.Unavailable, "unavailable")))
+	assert.Equal(t, http.StatusInternalServerError, HTTPStatusFromError(errors.New("not a status")))
+	assert.Equal(t, http.StatusOK, HTTPStatusFromError(nil))
 }
 
 func TestErrorFromInternalStatus(t *testing.T) {
@@ -385,6 +975,57 @@ func TestErrorFromInternalStatus(t *testing.T) {
 	assert.Equal(t, expected.Details(), actual.Details())
 }
 
+func TestBatchErrorsToStatuses(t *testing.T) {
+	statuses := BatchErrorsToStatuses([]error{
+		nil,
+		status.Error(codes.NotFound, "key not found"),
+		errors.New("connection reset"),
+	})
+
+	require.Len(t, statuses, 3)
+	assert.Equal(t, int32(codes.OK), statuses[0].GetCode())
+	assert.Empty(t, statuses[0].GetMessage())
+	assert.Equal(t, int32(codes.NotFound), statuses[1].GetCode())
+	assert.Equal(t, "key not found", statuses[1].GetMessage())
+	assert.Equal(t, int32(codes.Unknown), statuses[2].GetCode())
+	assert.Equal(t, "connection reset", statuses[2].GetMessage())
+
+	assert.Empty(t, BatchErrorsToStatuses(nil))
+}
+
+func TestHTTPStatusFromInternalStatus(t *testing.T) {
+	toInternal := func(err error) *internalv1pb.Status {
+		st := status.Convert(err).Proto()
+		return &internalv1pb.Status{
+			Code:    st.GetCode(),
+			Message: st.GetMessage(),
+			Details: st.GetDetails(),
+		}
+	}
+
+	t.Run("from http response code", func(t *testing.T) {
+		code, message, ok := HTTPStatusFromInternalStatus(toInternal(ErrorFromHTTPResponseCode(http.StatusTeapot, "no coffee")))
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusTeapot, code)
+		assert.Equal(t, "no coffee", message)
+	})
+
+	t.Run("without message", func(t *testing.T) {
+		code, message, ok := HTTPStatusFromInternalStatus(toInternal(ErrorPayloadTooLarge(10, 5)))
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+		assert.Empty(t, message)
+	})
+
+	t.Run("without http details", func(t *testing.T) {
+		_, _, ok := HTTPStatusFromInternalStatus(NewInternalStatus(codes.Internal, "error", &epb.DebugInfo{Detail: "debug"}))
+		assert.False(t, ok)
+
+		_, _, ok = HTTPStatusFromInternalStatus(nil)
+		assert.False(t, ok)
+	})
+}
+
 func TestProtobufToJSON(t *testing.T) {
 	tpb := &epb.DebugInfo{
 		StackEntries: []string{
@@ -405,6 +1046,69 @@ func TestProtobufToJSON(t *testing.T) {
 	assert.True(t, comp1 || comp2)
 }
 
+func TestJSONToProtobuf(t *testing.T) {
+	var res epb.DebugInfo
+	err := JSONToProtobuf(t.Context(), []byte(`{"stackEntries":["first stack","second stack"],"unknown":1}`), &res)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first stack", "second stack"}, res.GetStackEntries())
+
+	err = JSONToProtobuf(t.Context(), []byte(`{"stackEntries":`), &res)
+	require.Error(t, err)
+}
+
+func TestSerializationMetrics(t *testing.T) {
+	orig := *diag.DefaultGRPCMonitoring
+	t.Cleanup(func() {
+		*diag.DefaultGRPCMonitoring = orig
+	})
+
+	meter := view.NewMeter()
+	meter.Start()
+	t.Cleanup(meter.Stop)
+
+	diag.DefaultGRPCMonitoring.EnableSerializationMetrics()
+	require.NoError(t, diag.DefaultGRPCMonitoring.Init(meter, "test", view.Distribution(1, 10, 100)))
+
+	large := &epb.DebugInfo{
+		StackEntries: make([]string, 10_000),
+	}
+	for i := range large.StackEntries {
+		large.StackEntries[i] = strings.Repeat("x", 100)
+	}
+
+	jsonBody, err := ProtobufToJSONContext(t.Context(), large)
+	require.NoError(t, err)
+	var res epb.DebugInfo
+	require.NoError(t, JSONToProtobuf(t.Context(), jsonBody, &res))
+	assert.Len(t, res.GetStackEntries(), 10_000)
+
+	rows, err := meter.RetrieveData("grpc.io/serialization/latency")
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	for _, row := range rows {
+		assert.Equal(t, int64(1), row.Data.(*view.DistributionData).Count)
+	}
+	diag.RequireTagExist(t, rows, diag.NewTag("operation", "marshal"))
+	diag.RequireTagExist(t, rows, diag.NewTag("operation", "unmarshal"))
+
+	t.Run("serializer adds the time to the span of the request", func(t *testing.T) {
+		recorder := tracetest.NewSpanRecorder()
+		ctx, span := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test").Start(t.Context(), "test")
+
+		s, ok := SerializerForContentType(JSONContentType)
+		require.True(t, ok)
+		_, err := s.Marshal(ctx, large)
+		require.NoError(t, err)
+		span.End()
+
+		require.Len(t, recorder.Ended(), 1)
+		attrs := recorder.Ended()[0].Attributes()
+		assert.True(t, slices.ContainsFunc(attrs, func(kv attribute.KeyValue) bool {
+			return string(kv.Key) == diagConsts.DaprSerializationMsAttributeKey
+		}))
+	})
+}
+
 func TestWithCustomGrpcMetadata(t *testing.T) {
 	customMetadataKey := func(i int) string {
 		return fmt.Sprintf("customMetadataKey%d", i)
@@ -431,4 +1135,742 @@ func TestWithCustomGrpcMetadata(t *testing.T) {
 		// We assume only 1 value per key as the input map can only support string -> string mapping.
 		assert.Equal(t, customMetadataValue(i), val[0])
 	}
+
+	t.Run("mixed-case and whitespace-padded keys", func(t *testing.T) {
+		ctx := WithCustomGRPCMetadata(t.Context(), map[string]string{
+			" Content-Type ":   "application/json",
+			"CONTENT-LENGTH":   "10",
+			"  X-Custom-Key\t": "value",
+		})
+
+		ctxMd, ok := metadata.FromOutgoingContext(ctx)
+		require.True(t, ok)
+		assert.Equal(t, metadata.MD{"x-custom-key": []string{"value"}}, ctxMd)
+	})
+}
+
+func TestCoalesceMetadataKeys(t *testing.T) {
+	md := DaprInternalMetadata{
+		"X-Foo":        NewListStringValue("a", "b"),
+		"x-foo":        SingleValue("c"),
+		"Content-Type": SingleValue(JSONContentType),
+		"other":        SingleValue("value"),
+	}
+
+	for range 10 {
+		assert.Equal(t, DaprInternalMetadata{
+			"x-foo":        NewListStringValue("a", "b", "c"),
+			"content-type": SingleValue(JSONContentType),
+			"other":        SingleValue("value"),
+		}, CoalesceMetadataKeys(md))
+	}
+
+	// The original metadata is not modified.
+	assert.Len(t, md, 4)
+	assert.Equal(t, []string{"a", "b"}, md["X-Foo"].GetValues())
+}
+
+func TestCanonicalMetadataKey(t *testing.T) {
+	tests := map[string]string{
+		"content-type":     "content-type",
+		"Content-Type":     "content-type",
+		"CONTENT-TYPE":     "content-type",
+		" Content-Type ":   "content-type",
+		"\tX-Custom-Key\n": "x-custom-key",
+		"":                 "",
+	}
+	for key, expected := range tests {
+		assert.Equal(t, expected, CanonicalMetadataKey(key), key)
+	}
+
+	t.Run("internal metadata conversions", func(t *testing.T) {
+		md := DaprInternalMetadata{
+			" My-Header ":         {Values: []string{"value"}},
+			"Content-Type ":       {Values: []string{"application/json"}},
+			" Destination-App-ID": {Values: []string{"app"}},
+		}
+
+		headers := map[string]string{}
+		InternalMetadataToHTTPHeader(t.Context(), md, func(k, v string) {
+			headers[k] = v
+		})
+		assert.Equal(t, "value", headers["my-header"])
+		assert.NotContains(t, headers, "content-type")
+		assert.NotContains(t, headers, "destination-app-id")
+
+		grpcMD := InternalMetadataToGrpcMetadata(t.Context(), md, false)
+		assert.Equal(t, []string{"value"}, grpcMD.Get("my-header"))
+		assert.Empty(t, grpcMD.Get("destination-app-id"))
+	})
+}
+
+func TestEffectiveContentType(t *testing.T) {
+	t.Run("content-type header", func(t *testing.T) {
+		assert.Equal(t, JSONContentType, EffectiveContentType(DaprInternalMetadata{
+			"Content-Type": {Values: []string{JSONContentType}},
+		}))
+	})
+
+	t.Run("no content-type defaults to octet-stream", func(t *testing.T) {
+		assert.Equal(t, OctetStreamContentType, EffectiveContentType(DaprInternalMetadata{}))
+	})
+
+	t.Run("no content-type returns the configured default", func(t *testing.T) {
+		SetDefaultContentType(JSONContentType)
+		t.Cleanup(func() {
+			SetDefaultContentType(OctetStreamContentType)
+		})
+
+		assert.Equal(t, JSONContentType, EffectiveContentType(DaprInternalMetadata{
+			"custom-header":   {Values: []string{"value"}},
+			ContentTypeHeader: {Values: []string{""}},
+		}))
+		assert.Equal(t, ProtobufContentType, EffectiveContentType(DaprInternalMetadata{
+			ContentTypeHeader: {Values: []string{ProtobufContentType}},
+		}))
+	})
+}
+
+func TestVerifyCallerNamespace(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("public")
+	id, err := spiffe.FromStrings(td, "ns1", "app1")
+	require.NoError(t, err)
+
+	t.Run("matching namespace", func(t *testing.T) {
+		require.NoError(t, verifyCallerNamespace(id, DaprInternalMetadata{
+			CallerNamespaceHeader: {Values: []string{"ns1"}},
+		}))
+	})
+
+	t.Run("mismatching namespace", func(t *testing.T) {
+		err := verifyCallerNamespace(id, DaprInternalMetadata{
+			"Dapr-Caller-Namespace": {Values: []string{"ns2"}},
+		})
+		require.Error(t, err)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("missing identity", func(t *testing.T) {
+		md := DaprInternalMetadata{
+			CallerNamespaceHeader: {Values: []string{"ns1"}},
+		}
+		err := VerifyCallerNamespace(t.Context(), md)
+		require.Error(t, err)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		err = VerifyCallerNamespace(peer.NewContext(t.Context(), &peer.Peer{AuthInfo: credentials.TLSInfo{}}), md)
+		require.Error(t, err)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("no caller namespace header", func(t *testing.T) {
+		require.NoError(t, verifyCallerNamespace(id, DaprInternalMetadata{}))
+		require.NoError(t, VerifyCallerNamespace(t.Context(), DaprInternalMetadata{}))
+	})
+}
+
+func TestAPITokenFromMetadata(t *testing.T) {
+	t.Run("token is extracted", func(t *testing.T) {
+		token, ok := APITokenFromMetadata(DaprInternalMetadata{
+			DaprAPITokenHeader: {Values: []string{"secret"}},
+		})
+		assert.True(t, ok)
+		assert.Equal(t, "secret", token)
+	})
+
+	t.Run("key is matched case-insensitively", func(t *testing.T) {
+		token, ok := APITokenFromMetadata(DaprInternalMetadata{
+			"Dapr-Api-Token": {Values: []string{"secret"}},
+		})
+		assert.True(t, ok)
+		assert.Equal(t, "secret", token)
+	})
+
+	t.Run("no token", func(t *testing.T) {
+		token, ok := APITokenFromMetadata(DaprInternalMetadata{
+			"custom-header":    {Values: []string{"value"}},
+			DaprAPITokenHeader: {},
+		})
+		assert.False(t, ok)
+		assert.Empty(t, token)
+	})
+
+	t.Run("token is not forwarded", func(t *testing.T) {
+		md := DaprInternalMetadata{
+			DaprAPITokenHeader: {Values: []string{"secret"}},
+			"Dapr-Api-Token":   {Values: []string{"secret"}},
+			"custom-header":    {Values: []string{"value"}},
+		}
+
+		grpcMD := InternalMetadataToGrpcMetadata(t.Context(), md, true)
+		assert.Empty(t, grpcMD.Get(DaprAPITokenHeader))
+		assert.Empty(t, grpcMD.Get(DaprHeaderPrefix+DaprAPITokenHeader))
+		assert.Equal(t, []string{"value"}, grpcMD.Get("custom-header"))
+
+		headers := map[string]string{}
+		InternalMetadataToHTTPHeader(t.Context(), md, func(k, v string) {
+			headers[k] = v
+		})
+		assert.NotContains(t, headers, DaprAPITokenHeader)
+		assert.Equal(t, "value", headers["custom-header"])
+	})
+}
+
+func TestNewInternalStatus(t *testing.T) {
+	t.Run("without details", func(t *testing.T) {
+		st := NewInternalStatus(codes.NotFound, "not found")
+		assert.Equal(t, int32(codes.NotFound), st.GetCode())
+		assert.Equal(t, "not found", st.GetMessage())
+		assert.Empty(t, st.GetDetails())
+	})
+
+	t.Run("round trip with details", func(t *testing.T) {
+		badRequest := &epb.BadRequest{
+			FieldViolations: []*epb.BadRequest_FieldViolation{
+				{Field: "key", Description: "key is required"},
+			},
+		}
+		errorInfo := &epb.ErrorInfo{
+			Reason: "DAPR_STATE_KEY_MISSING",
+			Domain: "dapr.io",
+		}
+
+		st := NewInternalStatus(codes.InvalidArgument, "invalid request", badRequest, errorInfo)
+		require.Len(t, st.GetDetails(), 2)
+
+		s, ok := status.FromError(ErrorFromInternalStatus(st))
+		require.True(t, ok)
+		assert.Equal(t, codes.InvalidArgument, s.Code())
+		assert.Equal(t, "invalid request", s.Message())
+
+		details := s.Details()
+		require.Len(t, details, 2)
+		assert.True(t, proto.Equal(badRequest, details[0].(*epb.BadRequest)))
+		assert.True(t, proto.Equal(errorInfo, details[1].(*epb.ErrorInfo)))
+	})
+}
+
+func TestMetadataConversionMetrics(t *testing.T) {
+	orig := *diag.DefaultGRPCMonitoring
+	t.Cleanup(func() {
+		*diag.DefaultGRPCMonitoring = orig
+	})
+
+	meter := view.NewMeter()
+	meter.Start()
+	t.Cleanup(meter.Stop)
+
+	diag.DefaultGRPCMonitoring.EnableMetadataConversionMetrics()
+	require.NoError(t, diag.DefaultGRPCMonitoring.Init(meter, "test", view.Distribution(1, 10, 100)))
+
+	md := DaprInternalMetadata{
+		"custom-header": SingleValue("value"),
+	}
+	InternalMetadataToGrpcMetadata(t.Context(), md, true)
+	InternalMetadataToHTTPHeader(t.Context(), md, func(string, string) {})
+
+	rows, err := meter.RetrieveData("grpc.io/metadata/conversion_latency")
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	diag.RequireTagExist(t, rows, diag.NewTag("operation", "to_grpc"))
+	diag.RequireTagExist(t, rows, diag.NewTag("operation", "to_http"))
+}
+
+func TestErrorDetailsMetrics(t *testing.T) {
+	orig := *diag.DefaultGRPCMonitoring
+	t.Cleanup(func() {
+		*diag.DefaultGRPCMonitoring = orig
+	})
+
+	meter := view.NewMeter()
+	meter.Start()
+	t.Cleanup(meter.Stop)
+
+	require.NoError(t, diag.DefaultGRPCMonitoring.Init(meter, "test", view.Distribution(1, 10, 100)))
+
+	internalStatus := NewInternalStatus(codes.InvalidArgument, "invalid request",
+		&epb.ErrorInfo{Reason: "INVALID", Domain: "dapr.io"},
+		&epb.BadRequest{FieldViolations: []*epb.BadRequest_FieldViolation{{Field: "name", Description: "required"}}},
+	)
+	require.Error(t, ErrorFromInternalStatus(internalStatus))
+	require.Error(t, ErrorFromInternalStatus(internalStatus))
+
+	rows, err := meter.RetrieveData("grpc.io/error_details/count")
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	for _, typeURL := range []string{"type.googleapis.com/google.rpc.ErrorInfo", "type.googleapis.com/google.rpc.BadRequest"} {
+		diag.RequireTagExist(t, rows, diag.NewTag(diag.KeyErrorDetailType.Name(), typeURL))
+		assert.Equal(t, int64(2), diag.GetCountValueForObservationWithTagSet(rows, map[tag.Tag]bool{
+			diag.NewTag(diag.KeyErrorDetailType.Name(), typeURL): true,
+		}))
+	}
+}
+
+func benchmarkMetadata() DaprInternalMetadata {
+	return DaprInternalMetadata{
+		"content-type":                 SingleValue(JSONContentType),
+		"user-agent":                   SingleValue("benchmark"),
+		diagConsts.TraceparentHeader:   SingleValue("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"),
+		diagConsts.TracestateHeader:    SingleValue("congo=t61rcWkgMzE"),
+		ForwardedForHeader:             SingleValue("203.0.113.1"),
+		CallerIDHeader:                 SingleValue("caller"),
+		"x-custom-header":              SingleValue("value"),
+		"x-custom-multi-valued-header": NewListStringValue("a", "b", "c"),
+	}
+}
+
+func BenchmarkInternalMetadataToGrpcMetadata(b *testing.B) {
+	md := benchmarkMetadata()
+	ctx := b.Context()
+	b.ReportAllocs()
+	for b.Loop() {
+		InternalMetadataToGrpcMetadata(ctx, md, true)
+	}
+}
+
+func BenchmarkInternalMetadataToHTTPHeader(b *testing.B) {
+	md := benchmarkMetadata()
+	ctx := b.Context()
+	setHeader := func(string, string) {}
+	b.ReportAllocs()
+	for b.Loop() {
+		InternalMetadataToHTTPHeader(ctx, md, setHeader)
+	}
+}
+
+func TestForwardAuthorizationHeader(t *testing.T) {
+	md := DaprInternalMetadata{
+		"Authorization": SingleValue("Bearer secret"),
+		"custom-header": SingleValue("value"),
+	}
+	convert := func(t *testing.T) (metadata.MD, map[string]string) {
+		headers := map[string]string{}
+		InternalMetadataToHTTPHeader(t.Context(), md, func(k, v string) {
+			headers[k] = v
+		})
+		return InternalMetadataToGrpcMetadata(t.Context(), md, true), headers
+	}
+
+	t.Run("forward", func(t *testing.T) {
+		grpcMD, headers := convert(t)
+		assert.Equal(t, []string{"Bearer secret"}, grpcMD.Get(AuthorizationHeader))
+		assert.Equal(t, "Bearer secret", headers[AuthorizationHeader])
+	})
+
+	t.Run("strip", func(t *testing.T) {
+		SetForwardAuthorizationHeader(false)
+		t.Cleanup(func() {
+			SetForwardAuthorizationHeader(true)
+		})
+
+		grpcMD, headers := convert(t)
+		assert.Empty(t, grpcMD.Get(AuthorizationHeader))
+		assert.NotContains(t, headers, AuthorizationHeader)
+		assert.Equal(t, []string{"value"}, grpcMD.Get("custom-header"))
+		assert.Equal(t, "value", headers["custom-header"])
+	})
+}
+
+func TestReasonPhraseFromCode(t *testing.T) {
+	tests := map[codes.Code]string{
+		codes.OK:                 "OK",
+		codes.NotFound:           "NOT_FOUND",
+		codes.DeadlineExceeded:   "DEADLINE_EXCEEDED",
+		codes.InvalidArgument:    "INVALID_ARGUMENT",
+		codes.ResourceExhausted:  "RESOURCE_EXHAUSTED",
+		codes.Unauthenticated:    "UNAUTHENTICATED",
+		codes.FailedPrecondition: "FAILED_PRECONDITION",
+		codes.Code(99):           "UNKNOWN",
+	}
+	for code, expected := range tests {
+		assert.Equal(t, expected, ReasonPhraseFromCode(code), code.String())
+	}
+}
+
+func TestIsChunkedTransfer(t *testing.T) {
+	tests := []struct {
+		name string
+		md   DaprInternalMetadata
+		want bool
+	}{
+		{"chunked", DaprInternalMetadata{"transfer-encoding": SingleValue("chunked")}, true},
+		{"canonical key", DaprInternalMetadata{"Transfer-Encoding": SingleValue("Chunked")}, true},
+		{"chunked as final coding", DaprInternalMetadata{"transfer-encoding": SingleValue("gzip, chunked")}, true},
+		{"chunked in last value", DaprInternalMetadata{"transfer-encoding": NewListStringValue("gzip", "chunked")}, true},
+		{"chunked not final", DaprInternalMetadata{"transfer-encoding": SingleValue("chunked, gzip")}, false},
+		{"other coding", DaprInternalMetadata{"transfer-encoding": SingleValue("gzip")}, false},
+		{"no header", DaprInternalMetadata{"content-type": SingleValue(JSONContentType)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsChunkedTransfer(tt.md))
+		})
+	}
+
+	t.Run("header is not forwarded", func(t *testing.T) {
+		md := DaprInternalMetadata{
+			"transfer-encoding": SingleValue("chunked"),
+			"custom-header":     SingleValue("value"),
+		}
+
+		grpcMD := InternalMetadataToGrpcMetadata(t.Context(), md, true)
+		assert.Empty(t, grpcMD.Get(TransferEncodingHeader))
+		assert.Empty(t, grpcMD.Get(DaprHeaderPrefix+TransferEncodingHeader))
+
+		headers := map[string]string{}
+		InternalMetadataToHTTPHeader(t.Context(), md, func(k, v string) {
+			headers[k] = v
+		})
+		assert.NotContains(t, headers, TransferEncodingHeader)
+		assert.Equal(t, "value", headers["custom-header"])
+	})
+}
+
+func TestStrictTraceparent(t *testing.T) {
+	SetStrictTraceparent(true)
+	logDest := &bytes.Buffer{}
+	log.SetOutput(logDest)
+	t.Cleanup(func() {
+		SetStrictTraceparent(false)
+		log.SetOutput(os.Stdout)
+	})
+
+	convert := func(t *testing.T, md DaprInternalMetadata) map[string]string {
+		headers := map[string]string{}
+		InternalMetadataToHTTPHeader(t.Context(), md, func(k, v string) {
+			headers[k] = v
+		})
+		return headers
+	}
+
+	t.Run("valid traceparent", func(t *testing.T) {
+		logDest.Reset()
+		const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+		headers := convert(t, DaprInternalMetadata{
+			diagConsts.TraceparentHeader: SingleValue(traceparent),
+			diagConsts.TracestateHeader:  SingleValue("congo=t61rcWkgMzE"),
+		})
+		assert.Equal(t, traceparent, headers[diagConsts.TraceparentHeader])
+		assert.Equal(t, "congo=t61rcWkgMzE", headers[diagConsts.TracestateHeader])
+		assert.Empty(t, logDest.String())
+	})
+
+	t.Run("malformed traceparent", func(t *testing.T) {
+		logDest.Reset()
+		headers := convert(t, DaprInternalMetadata{
+			diagConsts.TraceparentHeader: SingleValue("00-not-a-trace-01"),
+			diagConsts.TracestateHeader:  SingleValue("congo=t61rcWkgMzE"),
+		})
+		assert.NotContains(t, headers, diagConsts.TraceparentHeader)
+		assert.NotContains(t, headers, diagConsts.TracestateHeader)
+		assert.Contains(t, logDest.String(), "Dropping malformed traceparent header")
+	})
+
+	t.Run("absent traceparent", func(t *testing.T) {
+		logDest.Reset()
+		headers := convert(t, DaprInternalMetadata{
+			"custom-header": SingleValue("value"),
+		})
+		assert.NotContains(t, headers, diagConsts.TraceparentHeader)
+		assert.Equal(t, "value", headers["custom-header"])
+		assert.Empty(t, logDest.String())
+	})
+
+	t.Run("malformed traceparent is passed through when not strict", func(t *testing.T) {
+		SetStrictTraceparent(false)
+		t.Cleanup(func() {
+			SetStrictTraceparent(true)
+		})
+
+		headers := convert(t, DaprInternalMetadata{
+			diagConsts.TraceparentHeader: SingleValue("00-not-a-trace-01"),
+		})
+		assert.Equal(t, "00-not-a-trace-01", headers[diagConsts.TraceparentHeader])
+	})
+}
+
+func TestStatusForPartialStream(t *testing.T) {
+	t.Run("no error", func(t *testing.T) {
+		require.NoError(t, StatusForPartialStream(3, nil))
+	})
+
+	t.Run("nothing sent", func(t *testing.T) {
+		err := status.Error(codes.Unavailable, "connection lost")
+		assert.Equal(t, err, StatusForPartialStream(0, err))
+	})
+
+	t.Run("some messages sent", func(t *testing.T) {
+		err := StatusForPartialStream(5, status.Error(codes.Unavailable, "connection lost"))
+
+		s, ok := status.FromError(err)
+		require.True(t, ok)
+		assert.Equal(t, codes.Unavailable, s.Code())
+		assert.Equal(t, "connection lost", s.Message())
+		require.Len(t, s.Details(), 1)
+		errInfo := s.Details()[0].(*epb.ErrorInfo)
+		assert.Equal(t, "PARTIAL_STREAM", errInfo.GetReason())
+		assert.Equal(t, "dapr.io", errInfo.GetDomain())
+		assert.Equal(t, "5", errInfo.GetMetadata()["stream.sent_messages"])
+	})
+
+	t.Run("existing details are kept", func(t *testing.T) {
+		err := StatusForPartialStream(1, ErrorPayloadTooLarge(10, 5))
+
+		s, _ := status.FromError(err)
+		assert.Equal(t, codes.ResourceExhausted, s.Code())
+		require.Len(t, s.Details(), 2)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, HTTPStatusFromError(err))
+		assert.Equal(t, "PARTIAL_STREAM", s.Details()[1].(*epb.ErrorInfo).GetReason())
+	})
+
+	t.Run("non-status error", func(t *testing.T) {
+		err := StatusForPartialStream(2, context.Canceled)
+		assert.Equal(t, codes.Canceled, status.Code(err))
+
+		err = StatusForPartialStream(2, errors.New("boom"))
+		s, _ := status.FromError(err)
+		assert.Equal(t, codes.Unknown, s.Code())
+		assert.Equal(t, "boom", s.Message())
+		require.Len(t, s.Details(), 1)
+	})
+}
+
+func TestSetContentLength(t *testing.T) {
+	t.Run("content-length of converted body", func(t *testing.T) {
+		md := DaprInternalMetadata{
+			ContentLengthHeader: SingleValue("5"),
+			"custom-header":     SingleValue("value"),
+		}
+		body, err := ProtobufToJSON(status.New(codes.NotFound, "not found").Proto())
+		require.NoError(t, err)
+
+		headers := map[string]string{}
+		setHeader := func(k, v string) {
+			headers[k] = v
+		}
+		InternalMetadataToHTTPHeader(t.Context(), md, setHeader)
+		assert.NotContains(t, headers, ContentLengthHeader)
+
+		SetContentLength(setHeader, len(body))
+		assert.Equal(t, strconv.Itoa(len(body)), headers[ContentLengthHeader])
+		assert.Equal(t, "value", headers["custom-header"])
+	})
+
+	t.Run("unknown length", func(t *testing.T) {
+		headers := map[string]string{}
+		SetContentLength(func(k, v string) {
+			headers[k] = v
+		}, -1)
+		assert.Empty(t, headers)
+	})
+}
+
+func TestFirstValidTraceBin(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{75, 249, 47, 53, 119, 179, 77, 166, 163, 206, 146, 157, 14, 14, 71, 54},
+		SpanID:     trace.SpanID{0, 240, 103, 170, 11, 169, 2, 183},
+		TraceFlags: trace.FlagsSampled,
+	})
+	valid := base64.StdEncoding.EncodeToString(diagUtils.BinaryFromSpanContext(sc))
+	undecodable := "!!garbage!!"
+	invalid := base64.StdEncoding.EncodeToString([]byte{10, 30, 50, 60})
+
+	t.Run("garbage then valid", func(t *testing.T) {
+		got, ok := FirstValidTraceBin([]string{undecodable, invalid, valid})
+		require.True(t, ok)
+		assert.Equal(t, sc, got)
+	})
+
+	t.Run("first valid wins", func(t *testing.T) {
+		other := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: trace.TraceID{1},
+			SpanID:  trace.SpanID{1},
+		})
+		got, ok := FirstValidTraceBin([]string{valid, base64.StdEncoding.EncodeToString(diagUtils.BinaryFromSpanContext(other))})
+		require.True(t, ok)
+		assert.Equal(t, sc, got)
+	})
+
+	t.Run("no valid value", func(t *testing.T) {
+		_, ok := FirstValidTraceBin([]string{undecodable, invalid})
+		assert.False(t, ok)
+		_, ok = FirstValidTraceBin(nil)
+		assert.False(t, ok)
+	})
+
+	t.Run("metadata conversion", func(t *testing.T) {
+		md := DaprInternalMetadata{
+			diagConsts.GRPCTraceContextKey: NewListStringValue(undecodable, valid),
+			ContentTypeHeader:              SingleValue("application/grpc"),
+		}
+
+		grpcMD := InternalMetadataToGrpcMetadata(t.Context(), md, true)
+		assert.Equal(t, []string{string(diagUtils.BinaryFromSpanContext(sc))}, grpcMD.Get(diagConsts.GRPCTraceContextKey))
+		assert.Equal(t, []string{diag.SpanContextToW3CString(sc)}, grpcMD.Get(diagConsts.TraceparentHeader))
+
+		headers := map[string]string{}
+		InternalMetadataToHTTPHeader(t.Context(), md, func(k, v string) {
+			headers[k] = v
+		})
+		assert.Equal(t, diag.SpanContextToW3CString(sc), headers[diagConsts.TraceparentHeader])
+	})
+}
+
+func TestIsCORSPreflight(t *testing.T) {
+	preflight := DaprInternalMetadata{
+		":method":                        {Values: []string{"OPTIONS"}},
+		"Origin":                         {Values: []string{"https://example.com"}},
+		"Access-Control-Request-Method":  {Values: []string{"POST"}},
+		"Access-Control-Request-Headers": {Values: []string{"content-type, x-custom"}},
+		"content-type":                   {Values: []string{"text/plain"}},
+	}
+
+	t.Run("preflight", func(t *testing.T) {
+		assert.True(t, IsCORSPreflight(preflight))
+	})
+
+	t.Run("CORS request headers are forwarded", func(t *testing.T) {
+		headers := map[string]string{}
+		InternalMetadataToHTTPHeader(t.Context(), preflight, func(k, v string) {
+			headers[k] = v
+		})
+		assert.Equal(t, "https://example.com", headers[OriginHeader])
+		assert.Equal(t, "POST", headers[AccessControlRequestMethodHeader])
+		assert.Equal(t, "content-type, x-custom", headers[AccessControlRequestHeadersHeader])
+	})
+
+	t.Run("OPTIONS without Access-Control-Request-Method", func(t *testing.T) {
+		assert.False(t, IsCORSPreflight(DaprInternalMetadata{
+			":method": {Values: []string{"OPTIONS"}},
+			"Origin":  {Values: []string{"https://example.com"}},
+		}))
+	})
+
+	t.Run("other method", func(t *testing.T) {
+		assert.False(t, IsCORSPreflight(DaprInternalMetadata{
+			":method":                       {Values: []string{"POST"}},
+			"Access-Control-Request-Method": {Values: []string{"POST"}},
+		}))
+	})
+}
+
+func TestHTTPMethodFromMetadata(t *testing.T) {
+	t.Run("gRPC-origin", func(t *testing.T) {
+		md := DaprInternalMetadata{
+			ContentTypeHeader: SingleValue(GRPCContentType),
+			":method":         SingleValue(http.MethodPost),
+		}
+		assert.Empty(t, HTTPMethodFromMetadata(md))
+	})
+
+	t.Run("HTTP-origin with reserved method", func(t *testing.T) {
+		md := DaprInternalMetadata{
+			ContentTypeHeader: SingleValue(JSONContentType),
+			":method":         SingleValue("get"),
+			MethodHeader:      SingleValue(http.MethodHead),
+		}
+		assert.Equal(t, http.MethodGet, HTTPMethodFromMetadata(md))
+	})
+
+	t.Run("HTTP-origin with method header", func(t *testing.T) {
+		md := DaprInternalMetadata{
+			"Dapr-Method": SingleValue(http.MethodHead),
+		}
+		assert.Equal(t, http.MethodHead, HTTPMethodFromMetadata(md))
+	})
+
+	t.Run("HTTP-origin without method", func(t *testing.T) {
+		md := DaprInternalMetadata{
+			ContentTypeHeader: SingleValue(JSONContentType),
+		}
+		assert.Empty(t, HTTPMethodFromMetadata(md))
+	})
+}
+
+func TestProtocolVersionFromMetadata(t *testing.T) {
+	t.Run("gRPC-origin", func(t *testing.T) {
+		md := DaprInternalMetadata{
+			ContentTypeHeader: SingleValue(GRPCContentType),
+			"user-agent":      SingleValue("grpc-go/1.60.0"),
+		}
+		assert.Equal(t, ProtocolVersionHTTP2, ProtocolVersionFromMetadata(md))
+	})
+
+	t.Run("HTTP/2-origin with pseudo-headers", func(t *testing.T) {
+		md := DaprInternalMetadata{
+			ContentTypeHeader: SingleValue(JSONContentType),
+			":authority":      SingleValue("localhost:3500"),
+			":method":         SingleValue(http.MethodGet),
+		}
+		assert.Equal(t, ProtocolVersionHTTP2, ProtocolVersionFromMetadata(md))
+	})
+
+	t.Run("HTTP/1.1-origin", func(t *testing.T) {
+		md := DaprInternalMetadata{
+			ContentTypeHeader: SingleValue(JSONContentType),
+			"Host":            SingleValue("localhost:3500"),
+			MethodHeader:      SingleValue(http.MethodGet),
+		}
+		assert.Equal(t, ProtocolVersionHTTP11, ProtocolVersionFromMetadata(md))
+	})
+
+	t.Run("empty metadata", func(t *testing.T) {
+		assert.Equal(t, ProtocolVersionHTTP11, ProtocolVersionFromMetadata(nil))
+	})
+}
+
+func TestLinkHeader(t *testing.T) {
+	links := []string{
+		`<https://api.example.com/items?page=2>; rel="next"`,
+		`<https://api.example.com/items?page=5>; rel="last"`,
+	}
+	md := DaprInternalMetadata{
+		"Link":            NewListStringValue(links...),
+		ContentTypeHeader: SingleValue(JSONContentType),
+	}
+
+	t.Run("HTTP to HTTP", func(t *testing.T) {
+		header := http.Header{}
+		InternalMetadataToHTTPHeader(t.Context(), md, header.Add)
+		assert.Equal(t, links, header.Values("Link"))
+		assert.Empty(t, header.Values(DaprHeaderPrefix+LinkHeader))
+	})
+
+	t.Run("HTTP to gRPC", func(t *testing.T) {
+		grpcMD := InternalMetadataToGrpcMetadata(t.Context(), md, true)
+		assert.Equal(t, links, grpcMD.Get(LinkHeader))
+		assert.Empty(t, grpcMD.Get(DaprHeaderPrefix+LinkHeader))
+	})
+}
+
+func TestFileServingHeaders(t *testing.T) {
+	md := DaprInternalMetadata{
+		"Content-Disposition": SingleValue(`attachment; filename="report.pdf"`),
+		"Content-Range":       SingleValue("bytes 0-1023/4096"),
+		"Accept-Ranges":       SingleValue("bytes"),
+		ContentTypeHeader:     SingleValue("application/pdf"),
+	}
+
+	// These headers are not permanent, so they were already forwarded unprefixed to HTTP apps.
+	t.Run("HTTP to HTTP", func(t *testing.T) {
+		header := http.Header{}
+		InternalMetadataToHTTPHeader(t.Context(), md, header.Add)
+		assert.Equal(t, `attachment; filename="report.pdf"`, header.Get("content-disposition"))
+		assert.Equal(t, "bytes 0-1023/4096", header.Get("content-range"))
+		assert.Equal(t, "bytes", header.Get("accept-ranges"))
+		for _, key := range []string{"content-disposition", "content-range", "accept-ranges"} {
+			assert.Empty(t, header.Values(DaprHeaderPrefix+key))
+		}
+	})
+
+	// Accept-Ranges is not a permanent HTTP header, so it is not prefixed in gRPC metadata either.
+	t.Run("HTTP to gRPC", func(t *testing.T) {
+		grpcMD := InternalMetadataToGrpcMetadata(t.Context(), md, true)
+		assert.Equal(t, []string{`attachment; filename="report.pdf"`}, grpcMD.Get("content-disposition"))
+		assert.Equal(t, []string{"bytes 0-1023/4096"}, grpcMD.Get("content-range"))
+		assert.Equal(t, []string{"bytes"}, grpcMD.Get("accept-ranges"))
+		for _, key := range []string{"content-disposition", "content-range", "accept-ranges"} {
+			assert.Empty(t, grpcMD.Get(DaprHeaderPrefix+key))
+		}
+	})
 }
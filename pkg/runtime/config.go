@@ -127,6 +127,8 @@ type Config struct {
 	Config                        []string
 	UnixDomainSocket              string
 	ReadBufferSize                int // In bytes
+	MaxInvocationChainLength      int
+	InvocationLoopByMethod        bool
 	DisableBuiltinK8sSecretStore  bool
 	AppHealthCheckPath            string
 	AppChannelAddress             string
@@ -167,6 +169,8 @@ type internalConfig struct {
 	unixDomainSocket             string
 	maxRequestBodySize           int // In bytes
 	readBufferSize               int // In bytes
+	maxInvocationChainLength     int
+	invocationLoopByMethod       bool
 	gracefulShutdownDuration     time.Duration
 	blockShutdownDuration        *time.Duration
 	enableAPILogging             *bool
@@ -366,6 +370,8 @@ func (c *Config) toInternal() (*internalConfig, error) {
 		unixDomainSocket:             c.UnixDomainSocket,
 		maxRequestBodySize:           c.MaxRequestSize,
 		readBufferSize:               c.ReadBufferSize,
+		maxInvocationChainLength:     c.MaxInvocationChainLength,
+		invocationLoopByMethod:       c.InvocationLoopByMethod,
 		enableAPILogging:             c.EnableAPILogging,
 		appConnectionConfig: config.AppConnectionConfig{
 			ChannelAddress:      c.AppChannelAddress,
@@ -502,6 +502,7 @@ func newDaprRuntime(ctx context.Context,
 			return errors.Join(errs...)
 		},
 		rt.stopTrace,
+		diag.DefaultGRPCMonitoring.Close,
 	); err != nil {
 		return nil, err
 	}
@@ -678,10 +679,7 @@ func createOtelResource(ctx context.Context, defaultServiceName string) *resourc
 	if err != nil {
 		log.Warnf("failed to create OpenTelemetry resource, using default: %v", err)
 		// Fallback without environment detection
-		r = resource.NewWithAttributes(
-			semconv.SchemaURL,
-			semconv.ServiceNameKey.String(defaultServiceName),
-		)
+		r = diag.AppIDResource(defaultServiceName)
 	}
 
 	return r
@@ -1033,6 +1031,9 @@ func (a *DaprRuntime) initDirectMessaging(resolver nr.Resolver) {
 		ReadBufferSize:     a.runtimeConfig.readBufferSize,
 		Resiliency:         a.resiliency,
 		CompStore:          a.compStore,
+
+		MaxInvocationChainLength: a.runtimeConfig.maxInvocationChainLength,
+		InvocationLoopByMethod:   a.runtimeConfig.invocationLoopByMethod,
 	})
 	a.runnerCloser.AddCloser(a.directMessaging)
 }